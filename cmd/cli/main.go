@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/matteo-nyapa/tech-challenge-acai/internal/pb"
@@ -19,6 +20,7 @@ func main() {
 		fmt.Println("  ask        Create a new conversation with assistant or continue an existing one")
 		fmt.Println("  list       List existing conversations")
 		fmt.Println("  show       Show conversation by ID")
+		fmt.Println("  summary    Summarize conversation by ID")
 	}
 
 	if len(os.Args) < 2 {
@@ -92,6 +94,8 @@ func main() {
 
 				cid = out.GetConversationId()
 				fmt.Printf("ASSISTANT:\n%s\n\n", out.GetReply())
+				printSuggestions(out.GetSuggestions())
+				resolvePendingActions(ctx, cli, reader, cid, out.GetPendingAction())
 				continue
 			}
 
@@ -106,6 +110,8 @@ func main() {
 			}
 
 			fmt.Printf("ASSISTANT:\n%s\n\n", out.GetReply())
+			printSuggestions(out.GetSuggestions())
+			resolvePendingActions(ctx, cli, reader, cid, out.GetPendingAction())
 		}
 
 	case "list":
@@ -146,5 +152,74 @@ func main() {
 		for _, msg := range resp.GetConversation().GetMessages() {
 			fmt.Printf("%s, %s:\n%s\n\n", msg.GetRole(), msg.GetTimestamp().AsTime().Format(time.TimeOnly), msg.GetContent())
 		}
+	case "summary":
+		if len(os.Args) < 3 {
+			fmt.Println("Error: Conversation ID is required")
+			os.Exit(1)
+		}
+
+		resp, err := cli.SummarizeConversation(ctx, &pb.SummarizeConversationRequest{
+			ConversationId: os.Args[2],
+		})
+
+		if err != nil {
+			fmt.Printf("Error summarizing conversation: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("TL;DR:\n%s\n\n", resp.GetSummary())
+		printList("Key decisions:", resp.GetKeyDecisions())
+		printList("Action items:", resp.GetActionItems())
+	}
+}
+
+// resolvePendingActions asks the user to approve the actions the assistant is waiting on.
+func resolvePendingActions(ctx context.Context, cli pb.ChatService, reader *bufio.Reader, cid string, action *pb.PendingAction) {
+	for action != nil {
+		fmt.Printf("Approve? [y/N]: ")
+		line, _, err := reader.ReadLine()
+		if err != nil {
+			fmt.Printf("Error reading input: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println()
+
+		answer := strings.ToLower(strings.TrimSpace(string(line)))
+		out, err := cli.ResolvePendingAction(ctx, &pb.ResolvePendingActionRequest{
+			ConversationId: cid,
+			ActionId:       action.GetId(),
+			Approve:        answer == "y" || answer == "yes",
+		})
+		if err != nil {
+			fmt.Printf("Error resolving pending action: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("ASSISTANT:\n%s\n\n", out.GetReply())
+		printSuggestions(out.GetSuggestions())
+		action = out.GetPendingAction()
+	}
+}
+
+func printSuggestions(suggestions []string) {
+	if len(suggestions) == 0 {
+		return
+	}
+
+	fmt.Println("You could also ask:")
+	for _, s := range suggestions {
+		fmt.Printf("  - %s\n", s)
+	}
+	fmt.Println()
+}
+
+func printList(title string, items []string) {
+	fmt.Println(title)
+	if len(items) == 0 {
+		fmt.Println("  (none)")
+	}
+	for _, item := range items {
+		fmt.Printf("  - %s\n", item)
 	}
+	fmt.Println()
 }
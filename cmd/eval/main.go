@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/assistant"
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/assistant/calendar"
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/assistant/tools"
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/eval"
+)
+
+func main() {
+	casesPath := flag.String("cases", "cmd/eval/golden.jsonl", "JSONL file with the golden questions")
+	provider := flag.String("provider", "", `provider to evaluate as "provider:model", defaults to the configured providers`)
+	out := flag.String("out", "eval-report.json", "where to write the report")
+	baseline := flag.String("baseline", "", "previous report to diff the run against")
+	timeout := flag.Duration("timeout", 10*time.Minute, "timeout for the whole run")
+	flag.Usage = func() {
+		fmt.Println("Usage: acai-eval [options]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	cases, err := eval.LoadCases(*casesPath)
+	if err != nil {
+		fmt.Printf("Error loading cases: %v\n", err)
+		os.Exit(1)
+	}
+
+	cfg := assistant.ConfigFromEnv()
+	name := "default"
+	if *provider != "" {
+		p, err := assistant.ParseProvider(*provider)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		cfg.Providers = []assistant.Provider{p}
+		name = *provider
+	}
+
+	// the same tools as the server, results are only cached when asked for since a cache shared across
+	// the cases skews their latencies
+	registry := assistant.DefaultTools(calendar.LoaderFromEnv(), tools.NewPlaces(tools.NewLRUCache(1000)))
+	if os.Getenv("TOOL_CACHE") == "memory" {
+		registry.EnableCache(tools.NewLRUCache(512))
+	}
+
+	runner := eval.Runner{
+		Provider: name,
+		Tools:    registry,
+		NewAssistant: func(reg *tools.Registry) *assistant.Assistant {
+			c := cfg
+			c.Tools = reg
+			return assistant.NewWithConfig(c)
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	report := runner.Run(ctx, cases)
+	for _, r := range report.Results {
+		status := "PASS"
+		if !r.Passed {
+			status = "FAIL"
+		}
+		fmt.Printf("%s %-30s %6dms tools=%v\n", status, r.ID, r.LatencyMs, r.ToolsCalled)
+		if r.Error != "" {
+			fmt.Printf("     error: %s\n", r.Error)
+		}
+		if len(r.MissingTools) > 0 {
+			fmt.Printf("     missing tools: %v\n", r.MissingTools)
+		}
+		if len(r.MissingSubstrings) > 0 {
+			fmt.Printf("     missing facts: %q\n", r.MissingSubstrings)
+		}
+	}
+	fmt.Printf("\n%d/%d passed, avg latency %dms\n", report.Passed, report.Total, report.AvgLatencyMs)
+
+	if err := eval.WriteReport(*out, report); err != nil {
+		fmt.Printf("Error writing report: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println("Report written to", *out)
+
+	if *baseline == "" {
+		return
+	}
+
+	prev, err := eval.ReadReport(*baseline)
+	if err != nil {
+		fmt.Printf("Error reading baseline: %v\n", err)
+		os.Exit(1)
+	}
+
+	diff := eval.Compare(prev, report)
+	fmt.Println()
+	diff.Print(os.Stdout)
+
+	if len(diff.Regressions) > 0 {
+		os.Exit(1)
+	}
+}
@@ -5,15 +5,22 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"os"
 
 	"github.com/gorilla/mux"
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/cassette"
 	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat"
 	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/assistant"
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/assistant/calendar"
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/assistant/tools"
 	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/model"
 	"github.com/matteo-nyapa/tech-challenge-acai/internal/httpx"
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/mcp"
 	"github.com/matteo-nyapa/tech-challenge-acai/internal/mongox"
 	"github.com/matteo-nyapa/tech-challenge-acai/internal/observability"
 	"github.com/matteo-nyapa/tech-challenge-acai/internal/pb"
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/weather"
+	"github.com/openai/openai-go/v2/option"
 	"github.com/twitchtv/twirp"
 )
 
@@ -37,7 +44,57 @@ func main() {
 
 	mongo := mongox.MustConnect()
 	repo := model.New(mongo)
-	assist := assistant.New()
+
+	cfg := assistant.ConfigFromEnv()
+
+	cas, err := cassette.FromEnv()
+	if err != nil {
+		panic(fmt.Errorf("failed to open HTTP cassette: %w", err))
+	}
+	if cas != nil {
+		cfg.Providers = assistant.ProvidersFromEnv(option.WithHTTPClient(cas.Client()))
+	}
+
+	var calendarOpts []calendar.LoaderOption
+	if cas != nil {
+		calendarOpts = append(calendarOpts, calendar.WithHTTPClient(cas.Client()))
+	}
+	calendars := calendar.LoaderFromEnv(calendarOpts...)
+	if regions, err := calendar.RegionsFromEnv(); err == nil {
+		calendars.Warm(ctx, regions.Sources()...)
+	}
+
+	// the places picked in a conversation are kept in Mongo, so they survive restarts
+	store := tools.NewMongoCache(mongo)
+	if err := store.EnsureIndexes(ctx); err != nil {
+		slog.Error("Failed to create tool cache indexes", "error", err)
+	}
+	places := tools.NewPlaces(store)
+
+	cfg.Tools = assistant.DefaultTools(calendars, places)
+	if cas != nil {
+		forecasts := weather.FromEnv(weather.WithHTTPClient(cas.Client()))
+		cfg.Tools.Register(tools.NewWeatherTool(forecasts, places))
+		cfg.Tools.Register(tools.NewCompareWeatherTool(forecasts, places))
+	}
+	cfg.Tools.Register(tools.NewCalendarEventTool(repo, publicURL()))
+	if path := os.Getenv("HTTP_TOOLS_CONFIG"); path != "" {
+		httpTools, err := tools.LoadHTTPTools(path)
+		if err != nil {
+			panic(fmt.Errorf("failed to load HTTP tools: %w", err))
+		}
+		for _, t := range httpTools {
+			cfg.Tools.Register(t)
+		}
+	}
+	for _, c := range mcp.RegisterFromEnv(ctx, cfg.Tools) {
+		defer c.Close()
+	}
+	if cache := toolCache(store); cache != nil {
+		cfg.Tools.EnableCache(cache)
+	}
+
+	assist := assistant.NewWithConfig(cfg)
 	server := chat.NewServer(repo, assist)
 
 	handler := mux.NewRouter()
@@ -56,9 +113,31 @@ func main() {
 		twirp.WithServerHooks(hooks),
 	)
 	handler.PathPrefix("/twirp/").Handler(twirpSrv)
+	handler.Handle("/calendars/{conversation_id}.ics", chat.CalendarFeed(repo)).Methods(http.MethodGet)
 
 	slog.Info("Starting the server...", "addr", ":8080")
 	if err := http.ListenAndServe(":8080", handler); err != nil {
 		panic(err)
 	}
 }
+
+// publicURL is the address clients reach the server at, PUBLIC_URL (default http://localhost:8080).
+func publicURL() string {
+	if v := os.Getenv("PUBLIC_URL"); v != "" {
+		return v
+	}
+	return "http://localhost:8080"
+}
+
+// toolCache picks the tool result cache from TOOL_CACHE: "memory", "mongo" (backed by store) or none
+// when unset.
+func toolCache(store *tools.MongoCache) tools.CacheStore {
+	switch os.Getenv("TOOL_CACHE") {
+	case "memory":
+		return tools.NewLRUCache(512)
+	case "mongo":
+		return store
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,302 @@
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"unicode/utf8"
+)
+
+type Mode string
+
+const (
+	ModeOff    Mode = "off"
+	ModeRecord Mode = "record"
+	ModeReplay Mode = "replay"
+)
+
+// sensitive query parameters and headers are never written to a cassette
+var (
+	redactedParams  = []string{"key", "api_key", "apikey", "token"}
+	recordedHeaders = []string{"Content-Type", "Retry-After", "ETag", "Last-Modified"}
+)
+
+type Request struct {
+	Method string          `json:"method"`
+	URL    string          `json:"url"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+type Response struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body"`
+}
+
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Cassette is an http.RoundTripper that records HTTP exchanges into a JSON file, or replays them from it.
+// Requests are matched by method, (redacted) URL and body, so a replay fails when the code sends another
+// prompt than the recorded one. Repeated requests get the recorded responses in order.
+type Cassette struct {
+	path string
+	mode Mode
+	real http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []Interaction
+	played       []bool
+}
+
+type file struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+func Open(path string, mode Mode) (*Cassette, error) {
+	c := &Cassette{
+		path: path,
+		mode: mode,
+		real: http.DefaultTransport,
+	}
+
+	switch mode {
+	case ModeRecord:
+		return c, nil
+	case ModeReplay:
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading cassette: %w", err)
+		}
+		var f file
+		if err := json.Unmarshal(data, &f); err != nil {
+			return nil, fmt.Errorf("parsing cassette %s: %w", path, err)
+		}
+		c.interactions = f.Interactions
+		c.played = make([]bool, len(f.Interactions))
+		return c, nil
+	default:
+		return nil, fmt.Errorf("unsupported cassette mode %q", mode)
+	}
+}
+
+// FromEnv opens the cassette configured with CASSETTE_MODE (record or replay) and CASSETTE_FILE.
+// It returns nil when cassettes are disabled.
+func FromEnv() (*Cassette, error) {
+	mode := Mode(os.Getenv("CASSETTE_MODE"))
+	if mode == "" || mode == ModeOff {
+		return nil, nil
+	}
+
+	path := os.Getenv("CASSETTE_FILE")
+	if path == "" {
+		return nil, errors.New("CASSETTE_FILE is required when CASSETTE_MODE is set")
+	}
+
+	slog.Info("HTTP cassette enabled", "mode", mode, "file", path)
+	return Open(path, mode)
+}
+
+func (c *Cassette) Mode() Mode { return c.mode }
+
+func (c *Cassette) Client() *http.Client {
+	return &http.Client{Transport: c}
+}
+
+func (c *Cassette) RoundTrip(req *http.Request) (*http.Response, error) {
+	if c.mode == ModeReplay {
+		return c.replay(req)
+	}
+	return c.record(req)
+}
+
+func (c *Cassette) replay(req *http.Request) (*http.Response, error) {
+	key := req.Method + " " + redact(req.URL)
+	reqBody, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	body := canonicalBody(rawJSON(reqBody))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	sameURL := false
+	for i, in := range c.interactions {
+		if c.played[i] || in.Request.Method+" "+in.Request.URL != key {
+			continue
+		}
+		sameURL = true
+		if canonicalBody(in.Request.Body) != body {
+			continue
+		}
+
+		c.played[i] = true
+		return in.Response.toHTTP(req), nil
+	}
+
+	if sameURL {
+		return nil, fmt.Errorf("cassette %s: no recorded response for %s with body %s", c.path, key, abbreviate(body))
+	}
+	return nil, fmt.Errorf("cassette %s: no recorded response for %s", c.path, key)
+}
+
+func (c *Cassette) record(req *http.Request) (*http.Response, error) {
+	reqBody, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.real.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	in := Interaction{
+		Request: Request{Method: req.Method, URL: redact(req.URL), Body: rawJSON(reqBody)},
+		Response: Response{
+			Status:  resp.StatusCode,
+			Headers: map[string]string{},
+			Body:    rawJSON(respBody),
+		},
+	}
+	for _, h := range recordedHeaders {
+		if v := resp.Header.Get(h); v != "" {
+			in.Response.Headers[h] = v
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.interactions = append(c.interactions, in)
+	if err := c.save(); err != nil {
+		slog.Error("Failed to save cassette", "file", c.path, "error", err)
+	}
+
+	return resp, nil
+}
+
+func (c *Cassette) save() error {
+	data, err := json.MarshalIndent(file{Interactions: c.interactions}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, append(data, '\n'), 0o644)
+}
+
+func (r Response) toHTTP(req *http.Request) *http.Response {
+	var body []byte
+	var s string
+	if json.Unmarshal(r.Body, &s) == nil {
+		// non JSON bodies (e.g. ICS files) are stored as JSON strings
+		body = []byte(s)
+	} else {
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, r.Body); err == nil {
+			body = buf.Bytes()
+		} else {
+			body = r.Body
+		}
+	}
+
+	h := http.Header{}
+	for k, v := range r.Headers {
+		h.Set(k, v)
+	}
+
+	return &http.Response{
+		StatusCode:    r.Status,
+		Status:        fmt.Sprintf("%d %s", r.Status, http.StatusText(r.Status)),
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        h,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// readBody returns the body of req and leaves it readable for the transport.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+	b, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	_ = req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(b))
+	return b, nil
+}
+
+// abbreviate keeps error messages readable when bodies carry whole prompts and tool lists.
+func abbreviate(s string) string {
+	const limit = 1000
+	if len(s) <= limit {
+		return s
+	}
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + "..."
+}
+
+// canonicalBody makes recorded and sent bodies comparable regardless of key order and whitespace.
+func canonicalBody(b json.RawMessage) string {
+	var v any
+	if err := json.Unmarshal(b, &v); err != nil {
+		return string(b)
+	}
+	out, _ := json.Marshal(v)
+	return string(out)
+}
+
+// rawJSON keeps JSON bodies readable in the cassette and stores anything else as a JSON string.
+func rawJSON(b []byte) json.RawMessage {
+	if len(b) == 0 {
+		return nil
+	}
+	if json.Valid(b) {
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, b); err == nil {
+			return buf.Bytes()
+		}
+	}
+	s, _ := json.Marshal(string(b))
+	return s
+}
+
+func redact(u *url.URL) string {
+	c := *u
+	q := c.Query()
+	for _, p := range redactedParams {
+		if q.Has(p) {
+			q.Set(p, "REDACTED")
+		}
+	}
+	c.RawQuery = q.Encode()
+	return c.String()
+}
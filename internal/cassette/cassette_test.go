@@ -0,0 +1,104 @@
+package cassette_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/cassette"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCassette_RecordThenReplay(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Path == "/calendar.ics" {
+			w.Header().Set("Content-Type", "text/calendar")
+			_, _ = io.WriteString(w, "BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"call":`+string(rune('0'+calls))+`}`)
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	rec, err := cassette.Open(path, cassette.ModeRecord)
+	require.NoError(t, err)
+	recorded := []string{
+		get(t, rec.Client(), srv.URL+"/current.json?key=secret&q=Porto"),
+		get(t, rec.Client(), srv.URL+"/current.json?key=secret&q=Porto"),
+		get(t, rec.Client(), srv.URL+"/calendar.ics"),
+	}
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "secret")
+	require.Contains(t, string(data), "key=REDACTED")
+
+	srv.Close()
+
+	rep, err := cassette.Open(path, cassette.ModeReplay)
+	require.NoError(t, err)
+	replayed := []string{
+		get(t, rep.Client(), srv.URL+"/current.json?q=Porto&key=other"),
+		get(t, rep.Client(), srv.URL+"/current.json?q=Porto&key=other"),
+		get(t, rep.Client(), srv.URL+"/calendar.ics"),
+	}
+	require.Equal(t, recorded, replayed)
+	require.True(t, strings.HasPrefix(replayed[2], "BEGIN:VCALENDAR"))
+
+	_, err = rep.Client().Get(srv.URL + "/current.json?q=Porto")
+	require.Error(t, err, "requests beyond the recorded ones should fail")
+}
+
+func get(t *testing.T, c *http.Client, url string) string {
+	t.Helper()
+	resp, err := c.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestCassette_ReplayMatchesBodies(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(b)
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	rec, err := cassette.Open(path, cassette.ModeRecord)
+	require.NoError(t, err)
+	post(t, rec.Client(), srv.URL+"/chat", `{"prompt":"first","n":1}`)
+	post(t, rec.Client(), srv.URL+"/chat", `{"prompt":"second","n":2}`)
+	srv.Close()
+
+	rep, err := cassette.Open(path, cassette.ModeReplay)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"prompt":"second","n":2}`, post(t, rep.Client(), srv.URL+"/chat", `{ "n": 2, "prompt": "second" }`))
+
+	_, err = rep.Client().Post(srv.URL+"/chat", "application/json", strings.NewReader(`{"prompt":"other"}`))
+	require.ErrorContains(t, err, `with body {"prompt":"other"}`, "another prompt must not get the recorded answer")
+
+	require.JSONEq(t, `{"prompt":"first","n":1}`, post(t, rep.Client(), srv.URL+"/chat", `{"prompt":"first","n":1}`))
+}
+
+func post(t *testing.T, c *http.Client, url, body string) string {
+	t.Helper()
+	resp, err := c.Post(url, "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(b)
+}
@@ -4,20 +4,114 @@ import (
 	"context"
 	"errors"
 	"log/slog"
+	"os"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/assistant/calendar"
 	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/assistant/tools"
 	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/model"
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/weather"
 	"github.com/openai/openai-go/v2"
 )
 
+type Config struct {
+	// Providers are tried in order, the first one is the primary and the rest are fallbacks.
+	Providers []Provider
+	Retry     RetryPolicy
+
+	// Tools available to the model, DefaultTools with a calendar loader of its own when nil.
+	Tools *tools.Registry
+
+	// ToolConcurrency limits how many tool calls of a single completion run at the same time.
+	ToolConcurrency int
+	// ToolTimeout bounds every single tool call, ToolTimeouts overrides it per tool name.
+	ToolTimeout  time.Duration
+	ToolTimeouts map[string]time.Duration
+
+	Limits Limits
+}
+
+func ConfigFromEnv() Config {
+	cfg := Config{
+		Providers:       ProvidersFromEnv(),
+		Retry:           RetryPolicyFromEnv(),
+		ToolConcurrency: defaultToolConcurrency,
+		ToolTimeout:     defaultToolTimeout,
+		ToolTimeouts:    ToolTimeoutsFromEnv(),
+		Limits:          LimitsFromEnv(),
+	}
+
+	if v, err := strconv.Atoi(os.Getenv("ASSISTANT_TOOL_CONCURRENCY")); err == nil && v > 0 {
+		cfg.ToolConcurrency = v
+	}
+	if v, err := time.ParseDuration(os.Getenv("ASSISTANT_TOOL_TIMEOUT")); err == nil && v > 0 {
+		cfg.ToolTimeout = v
+	}
+
+	return cfg
+}
+
 type Assistant struct {
-	cli openai.Client
+	providers       []Provider
+	retry           RetryPolicy
+	metrics         *llmMetrics
+	tools           *tools.Registry
+	toolConcurrency int
+	toolTimeout     time.Duration
+	toolTimeouts    map[string]time.Duration
+	limits          Limits
 }
 
 func New() *Assistant {
-	return &Assistant{cli: openai.NewClient()}
+	return NewWithConfig(ConfigFromEnv())
+}
+
+func NewWithConfig(cfg Config) *Assistant {
+	if cfg.Tools == nil {
+		cfg.Tools = DefaultTools(calendar.NewLoader(0), tools.NewPlaces(tools.NewLRUCache(1000)))
+	}
+	if cfg.ToolConcurrency <= 0 {
+		cfg.ToolConcurrency = defaultToolConcurrency
+	}
+	if cfg.ToolTimeout <= 0 {
+		cfg.ToolTimeout = defaultToolTimeout
+	}
+	if cfg.Limits == (Limits{}) {
+		cfg.Limits = DefaultLimits()
+	}
+
+	return &Assistant{
+		providers:       cfg.Providers,
+		retry:           cfg.Retry,
+		metrics:         newLLMMetrics(),
+		tools:           cfg.Tools,
+		toolConcurrency: cfg.ToolConcurrency,
+		toolTimeout:     cfg.ToolTimeout,
+		toolTimeouts:    cfg.ToolTimeouts,
+		limits:          cfg.Limits,
+	}
+}
+
+// DefaultTools are the built-in tools, the holiday ones load their calendars with calendars and the
+// weather ones remember the places of each conversation in places.
+func DefaultTools(calendars *calendar.Loader, places *tools.Places) *tools.Registry {
+	regions, err := calendar.RegionsFromEnv()
+	if err != nil {
+		slog.Error("Failed to load calendar regions, using the defaults", "error", err)
+		regions = calendar.DefaultRegions()
+	}
+
+	forecasts := weather.FromEnv()
+	return tools.NewRegistry(
+		tools.NewWeatherTool(forecasts, places),
+		tools.NewCompareWeatherTool(forecasts, places),
+		tools.NewTodayTool(),
+		tools.NewHolidaysTool(regions, calendars),
+		tools.NewBusinessDaysTool(regions, calendars),
+	)
 }
 
 func (a *Assistant) Title(ctx context.Context, conv *model.Conversation) (string, error) {
@@ -45,8 +139,7 @@ func (a *Assistant) Title(ctx context.Context, conv *model.Conversation) (string
 		openai.UserMessage(firstUser),
 	}
 
-	resp, err := a.cli.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
-		Model:    openai.ChatModelGPT4_1,
+	resp, err := a.complete(ctx, openai.ChatCompletionNewParams{
 		Messages: msgs,
 	})
 	if err != nil {
@@ -72,16 +165,15 @@ func (a *Assistant) Reply(ctx context.Context, conv *model.Conversation) (string
 	}
 
 	slog.InfoContext(ctx, "Generating reply for conversation", "conversation_id", conv.ID)
-
-	reg := tools.NewRegistry(
-		tools.WeatherTool{},
-		tools.TodayTool{},
-		tools.HolidaysTool{},
-	)
+	ctx = tools.WithConversation(ctx, conv.ID.Hex())
+	ctx = tools.WithPreferences(ctx, conv.Preferences)
 
 	msgs := []openai.ChatCompletionMessageParamUnion{
 		openai.SystemMessage("You are a helpful, concise AI assistant. Provide accurate, safe, and clear responses."),
 	}
+	if prefs := conv.Preferences.Describe(); prefs != "" {
+		msgs = append(msgs, openai.SystemMessage(prefs))
+	}
 	for _, m := range conv.Messages {
 		switch m.Role {
 		case model.RoleUser:
@@ -91,11 +183,22 @@ func (a *Assistant) Reply(ctx context.Context, conv *model.Conversation) (string
 		}
 	}
 
-	for i := 0; i < 15; i++ {
-		resp, err := a.cli.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
-			Model:    openai.ChatModelGPT4_1,
+	return a.run(ctx, conv, msgs)
+}
+
+// run is the tool loop of a reply: it completes msgs, runs the requested tools and repeats until the
+// model answers, a limit is reached or a tool needs the user approval.
+func (a *Assistant) run(ctx context.Context, conv *model.Conversation, msgs []openai.ChatCompletionMessageParamUnion) (string, error) {
+	budget := newReplyBudget(a.limits)
+	for {
+		reason, ok := budget.next()
+		if !ok {
+			return a.finalAnswer(ctx, conv, msgs, reason)
+		}
+
+		resp, err := a.complete(ctx, openai.ChatCompletionNewParams{
 			Messages: msgs,
-			Tools:    reg.AsOpenAITools(),
+			Tools:    a.tools.AsOpenAITools(),
 		})
 		if err != nil {
 			return "", err
@@ -109,27 +212,52 @@ func (a *Assistant) Reply(ctx context.Context, conv *model.Conversation) (string
 			return message.Content, nil
 		}
 
-		msgs = append(msgs, message.ToParam())
-		for _, call := range message.ToolCalls {
-			slog.InfoContext(ctx, "Tool call received", "name", call.Function.Name, "args", call.Function.Arguments)
+		if reason, ok := budget.admit(message.ToolCalls); !ok {
+			return a.finalAnswer(ctx, conv, msgs, reason)
+		}
 
-			t, ok := reg.Get(call.Function.Name)
-			if !ok {
-				msgs = append(msgs, openai.ToolMessage("unknown tool: "+call.Function.Name, call.ID))
-				continue
+		var now, confirm []openai.ChatCompletionMessageToolCallUnion
+		for _, call := range message.ToolCalls {
+			if a.tools.RequiresConfirmation(call.Function.Name) {
+				confirm = append(confirm, call)
+			} else {
+				now = append(now, call)
 			}
+		}
 
-			payload, err := t.Call(ctx, call.Function.Arguments)
-			if err != nil {
-				msgs = append(msgs, openai.ToolMessage("tool error: "+err.Error(), call.ID))
-				continue
-			}
+		toolCtx, cancel := context.WithDeadline(ctx, budget.deadline)
+		msgs = append(msgs, message.ToParam())
+		msgs = append(msgs, a.runTools(toolCtx, a.tools, now)...)
+		cancel()
 
-			msgs = append(msgs, openai.ToolMessage(payload, call.ID))
+		if len(confirm) > 0 {
+			return a.pause(ctx, conv, msgs, confirm)
 		}
 	}
+}
+
+// finalAnswer asks the model to answer with the tool results gathered so far, without calling more tools.
+func (a *Assistant) finalAnswer(ctx context.Context, conv *model.Conversation, msgs []openai.ChatCompletionMessageParamUnion, reason string) (string, error) {
+	slog.WarnContext(ctx, "Reply limit reached, asking for a final answer", "conversation_id", conv.ID, "reason", reason)
+
+	msgs = append(msgs, openai.SystemMessage(
+		"Tool usage has been stopped because the assistant "+reason+". Do not call any more tools. "+
+			"Answer the user with the information you already have and briefly mention what you could not find out.",
+	))
+
+	resp, err := a.complete(ctx, openai.ChatCompletionNewParams{
+		Messages:   msgs,
+		Tools:      a.tools.AsOpenAITools(),
+		ToolChoice: openai.ChatCompletionToolChoiceOptionUnionParam{OfAuto: openai.String(string(openai.ChatCompletionToolChoiceOptionAutoNone))},
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(resp.Choices) == 0 || strings.TrimSpace(resp.Choices[0].Message.Content) == "" {
+		return "", errors.New("empty final answer returned by OpenAI")
+	}
 
-	return "", errors.New("too many tool calls, unable to generate reply")
+	return resp.Choices[0].Message.Content, nil
 }
 
 func normalizeTitle(s string) string {
@@ -5,14 +5,14 @@ import (
 	"strings"
 	"testing"
 
-	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/assistant"
 	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/model"
+	. "github.com/matteo-nyapa/tech-challenge-acai/internal/chat/testing"
 	"github.com/stretchr/testify/require"
 )
 
 func TestAssistant_Title_GeneratesConciseSummary(t *testing.T) {
 	ctx := context.Background()
-	a := assistant.New()
+	a := NewRecordedAssistant(t, "title_barcelona")
 
 	conv := &model.Conversation{
 		Messages: []*model.Message{
@@ -31,3 +31,58 @@ func TestAssistant_Title_GeneratesConciseSummary(t *testing.T) {
 	require.NotContains(t, title, "?", "title should not contain a question mark")
 	require.False(t, strings.HasSuffix(title, "."), "title should not end with punctuation")
 }
+
+func TestAssistant_Reply_UsesWeatherTool(t *testing.T) {
+	ctx := context.Background()
+	a := NewRecordedAssistant(t, "reply_weather_barcelona")
+
+	conv := &model.Conversation{
+		Messages: []*model.Message{
+			{
+				Role:    model.RoleUser,
+				Content: "Will it rain in Barcelona tomorrow?",
+			},
+		},
+	}
+
+	reply, err := a.Reply(ctx, conv)
+	require.NoError(t, err)
+	require.Contains(t, reply, "Barcelona")
+	require.Contains(t, reply, "rain")
+}
+
+func TestAssistant_Suggest_ReturnsAtMostThreeFollowUps(t *testing.T) {
+	ctx := context.Background()
+	a := NewRecordedAssistant(t, "suggest_weather_barcelona")
+
+	conv := &model.Conversation{
+		Messages: []*model.Message{
+			{Role: model.RoleUser, Content: "What is the weather in Barcelona today?"},
+			{Role: model.RoleAssistant, Content: "Currently in Barcelona: 20.4°C, Sunny, wind 10 km/h."},
+		},
+	}
+
+	suggestions, err := a.Suggest(ctx, conv)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Show the 5-day forecast", "Will it rain this weekend?", "What about Madrid?"}, suggestions)
+}
+
+func TestAssistant_Summarize_ExtractsDecisionsAndActionItems(t *testing.T) {
+	ctx := context.Background()
+	a := NewRecordedAssistant(t, "summarize_barcelona_trip")
+
+	conv := &model.Conversation{
+		Messages: []*model.Message{
+			{Role: model.RoleUser, Content: "I'm flying to Barcelona on Friday, what's the weather going to be like?"},
+			{Role: model.RoleAssistant, Content: "Light rain is expected on Friday in Barcelona, between 16°C and 21°C."},
+			{Role: model.RoleUser, Content: "Ok, I'll take an umbrella then."},
+		},
+	}
+
+	summary, err := a.Summarize(ctx, conv)
+	require.NoError(t, err)
+	require.Contains(t, summary.Summary, "Barcelona")
+	require.Equal(t, []string{"Travel to Barcelona on Friday"}, summary.KeyDecisions)
+	require.Equal(t, []string{"Pack an umbrella for Friday"}, summary.ActionItems)
+	require.Equal(t, 3, summary.MessageCount)
+}
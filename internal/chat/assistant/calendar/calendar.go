@@ -2,19 +2,15 @@ package calendar
 
 import (
 	"context"
-	"fmt"
-	"log/slog"
-
-	ics "github.com/arran4/golang-ical"
+	"time"
 )
 
-func LoadCalendar(ctx context.Context, link string) ([]*ics.VEvent, error) {
-	slog.InfoContext(ctx, "Loading calendar", "link", link)
-
-	cal, err := ics.ParseCalendarFromUrl(link, ctx)
+// Events reads the calendar at link through the cache of l and returns its occurrences in [from, to),
+// see Expand.
+func (l *Loader) Events(ctx context.Context, link string, from, to time.Time) ([]Event, error) {
+	events, err := l.Load(ctx, link)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse calendar: %w", err)
+		return nil, err
 	}
-
-	return cal.Events(), nil
+	return Expand(events, from, to), nil
 }
@@ -0,0 +1,256 @@
+package calendar
+
+import (
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	ics "github.com/arran4/golang-ical"
+)
+
+// defaultHorizon bounds the expansion of recurring events when no window end is given.
+const defaultHorizon = 2 * 366 * 24 * time.Hour
+
+// Event is an occurrence of a calendar event, recurring events yield one Event per occurrence.
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Location    string
+	// Start and End are in the time zone of the event, midnight UTC for all-day events. End is
+	// exclusive: an all-day event of a single day ends at midnight of the next one.
+	Start     time.Time
+	End       time.Time
+	AllDay    bool
+	Recurring bool
+}
+
+// Overlaps reports whether the event takes place in [from, to), zero bounds are open.
+func (e Event) Overlaps(from, to time.Time) bool {
+	if !to.IsZero() && !e.Start.Before(to) {
+		return false
+	}
+	if !from.IsZero() && !e.End.After(from) && !(e.End.Equal(e.Start) && e.Start.Equal(from)) {
+		return false
+	}
+	return true
+}
+
+// Days lists the YYYY-MM-DD dates the event spans.
+func (e Event) Days() []string {
+	last := e.End
+	if last.After(e.Start) {
+		last = last.Add(-time.Nanosecond)
+	}
+
+	var days []string
+	y, m, d := e.Start.Date()
+	for day := time.Date(y, m, d, 0, 0, 0, 0, time.UTC); ; day = day.AddDate(0, 0, 1) {
+		days = append(days, day.Format(time.DateOnly))
+		if day.Format(time.DateOnly) >= last.Format(time.DateOnly) {
+			return days
+		}
+	}
+}
+
+// Expand normalizes events and expands their RRULE, RDATE and EXDATE properties into the occurrences
+// that overlap [from, to), sorted by start. A zero to expands recurrences up to two years from now.
+func Expand(events []*ics.VEvent, from, to time.Time) []Event {
+	horizon := to
+	if horizon.IsZero() {
+		horizon = time.Now().Add(defaultHorizon)
+	}
+
+	// occurrences moved or edited by a RECURRENCE-ID event replace the generated ones
+	overridden := map[string]bool{}
+	for _, ve := range events {
+		if p := ve.GetProperty(ics.ComponentPropertyRecurrenceId); p != nil {
+			if t, _, err := parseTimeProp(p); err == nil {
+				overridden[ve.Id()+"|"+t.UTC().String()] = true
+			}
+		}
+	}
+
+	var out []Event
+	for _, ve := range events {
+		occurrences, err := expandEvent(ve, horizon, overridden)
+		if err != nil {
+			slog.Warn("Skipping calendar event", "uid", ve.Id(), "error", err)
+			continue
+		}
+		for _, e := range occurrences {
+			if e.Overlaps(from, to) {
+				out = append(out, e)
+			}
+		}
+	}
+
+	slices.SortStableFunc(out, func(a, b Event) int { return a.Start.Compare(b.Start) })
+	return out
+}
+
+func expandEvent(ve *ics.VEvent, horizon time.Time, overridden map[string]bool) ([]Event, error) {
+	startProp := ve.GetProperty(ics.ComponentPropertyDtStart)
+	if startProp == nil {
+		return nil, fmt.Errorf("missing DTSTART")
+	}
+	start, allDay, err := parseTimeProp(startProp)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DTSTART: %w", err)
+	}
+
+	end := start
+	switch {
+	case ve.GetProperty(ics.ComponentPropertyDtEnd) != nil:
+		if end, _, err = parseTimeProp(ve.GetProperty(ics.ComponentPropertyDtEnd)); err != nil {
+			return nil, fmt.Errorf("invalid DTEND: %w", err)
+		}
+	case ve.GetProperty(ics.ComponentPropertyDuration) != nil:
+		d, err := parseDuration(ve.GetProperty(ics.ComponentPropertyDuration).Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid DURATION: %w", err)
+		}
+		end = start.Add(d)
+	case allDay:
+		end = start.AddDate(0, 0, 1)
+	}
+	if end.Before(start) {
+		end = start
+	}
+
+	base := Event{
+		UID:         ve.Id(),
+		Summary:     propValue(ve, ics.ComponentPropertySummary),
+		Description: propValue(ve, ics.ComponentPropertyDescription),
+		Location:    propValue(ve, ics.ComponentPropertyLocation),
+		Start:       start,
+		End:         end,
+		AllDay:      allDay,
+		Recurring:   ve.GetProperty(ics.ComponentPropertyRecurrenceId) != nil,
+	}
+
+	rrules := ve.GetProperties(ics.ComponentPropertyRrule)
+	rdates := ve.GetProperties(ics.ComponentPropertyRdate)
+	if base.Recurring || len(rrules)+len(rdates) == 0 {
+		return []Event{base}, nil
+	}
+
+	starts := []time.Time{start}
+	for _, p := range rrules {
+		rule, err := parseRRule(p.Value, start.Location())
+		if err != nil {
+			return nil, fmt.Errorf("invalid RRULE: %w", err)
+		}
+		starts = append(starts, rule.occurrences(start, horizon)...)
+	}
+	for _, p := range rdates {
+		times, err := parseTimeList(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RDATE: %w", err)
+		}
+		starts = append(starts, times...)
+	}
+
+	var excluded []time.Time
+	for _, p := range ve.GetProperties(ics.ComponentPropertyExdate) {
+		times, err := parseTimeList(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid EXDATE: %w", err)
+		}
+		excluded = append(excluded, times...)
+	}
+
+	slices.SortFunc(starts, func(a, b time.Time) int { return a.Compare(b) })
+	starts = slices.CompactFunc(starts, time.Time.Equal)
+
+	length := end.Sub(start)
+	var out []Event
+	for _, s := range starts {
+		if slices.ContainsFunc(excluded, s.Equal) || overridden[base.UID+"|"+s.UTC().String()] {
+			continue
+		}
+		e := base
+		e.Start, e.End, e.Recurring = s, s.Add(length), true
+		out = append(out, e)
+	}
+	return out, nil
+}
+
+func propValue(ve *ics.VEvent, p ics.ComponentProperty) string {
+	if prop := ve.GetProperty(p); prop != nil {
+		return prop.Value
+	}
+	return ""
+}
+
+// parseTimeProp parses a DATE or DATE-TIME property honoring its TZID. Floating times are local.
+func parseTimeProp(p *ics.IANAProperty) (time.Time, bool, error) {
+	return parseTimeValue(p.Value, propLocation(p))
+}
+
+func parseTimeList(p *ics.IANAProperty) ([]time.Time, error) {
+	loc := propLocation(p)
+
+	var out []time.Time
+	for _, v := range strings.Split(p.Value, ",") {
+		if strings.Contains(v, "/") {
+			// PERIOD values, their start is enough to know the occurrence
+			v, _, _ = strings.Cut(v, "/")
+		}
+		t, _, err := parseTimeValue(strings.TrimSpace(v), loc)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+func propLocation(p *ics.IANAProperty) *time.Location {
+	if tzid := p.ICalParameters["TZID"]; len(tzid) > 0 {
+		if loc, err := time.LoadLocation(strings.Trim(tzid[0], `"`)); err == nil {
+			return loc
+		}
+	}
+	return time.Local
+}
+
+func parseTimeValue(v string, loc *time.Location) (time.Time, bool, error) {
+	switch {
+	case len(v) == 8:
+		t, err := time.ParseInLocation("20060102", v, time.UTC)
+		return t, true, err
+	case strings.HasSuffix(v, "Z"):
+		t, err := time.ParseInLocation("20060102T150405Z", v, time.UTC)
+		return t, false, err
+	default:
+		t, err := time.ParseInLocation("20060102T150405", v, loc)
+		return t, false, err
+	}
+}
+
+var durationPattern = regexp.MustCompile(`^([+-])?P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// parseDuration parses RFC 5545 durations such as P1D, PT1H30M or P2W.
+func parseDuration(v string) (time.Duration, error) {
+	m := durationPattern.FindStringSubmatch(strings.ToUpper(strings.TrimSpace(v)))
+	if m == nil {
+		return 0, fmt.Errorf("invalid duration %q", v)
+	}
+
+	var d time.Duration
+	for i, unit := range []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second} {
+		if m[i+2] != "" {
+			n, _ := strconv.Atoi(m[i+2])
+			d += time.Duration(n) * unit
+		}
+	}
+	if m[1] == "-" {
+		d = -d
+	}
+	return d, nil
+}
@@ -0,0 +1,146 @@
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	ics "github.com/arran4/golang-ical"
+	"github.com/stretchr/testify/require"
+)
+
+func parseEvents(t *testing.T, body string) []*ics.VEvent {
+	cal, err := ics.ParseCalendar(strings.NewReader("BEGIN:VCALENDAR\nVERSION:2.0\nPRODID:-//Test//EN\n" + body + "END:VCALENDAR\n"))
+	require.NoError(t, err)
+	return cal.Events()
+}
+
+func starts(events []Event) []string {
+	var out []string
+	for _, e := range events {
+		out = append(out, e.Start.Format(time.DateTime))
+	}
+	return out
+}
+
+func date(s string) time.Time {
+	t, _ := time.Parse(time.DateOnly, s)
+	return t
+}
+
+func TestExpand_YearlyRules(t *testing.T) {
+	events := parseEvents(t, `BEGIN:VEVENT
+UID:thanksgiving
+DTSTART;VALUE=DATE:20201126
+RRULE:FREQ=YEARLY;BYMONTH=11;BYDAY=4TH
+SUMMARY:Thanksgiving
+END:VEVENT
+BEGIN:VEVENT
+UID:memorial
+DTSTART;VALUE=DATE:20200525
+RRULE:FREQ=YEARLY;BYMONTH=5;BYDAY=-1MO
+SUMMARY:Memorial Day
+END:VEVENT
+BEGIN:VEVENT
+UID:diada
+DTSTART;VALUE=DATE:20200911
+RRULE:FREQ=YEARLY
+EXDATE;VALUE=DATE:20240911
+SUMMARY:Diada
+END:VEVENT
+`)
+
+	got := Expand(events, date("2024-01-01"), date("2026-01-01"))
+	require.Equal(t, []string{
+		"2024-05-27 00:00:00", "2024-11-28 00:00:00",
+		"2025-05-26 00:00:00", "2025-09-11 00:00:00", "2025-11-27 00:00:00",
+	}, starts(got))
+	require.Equal(t, "Memorial Day", got[0].Summary)
+	require.True(t, got[0].AllDay)
+	require.True(t, got[0].Recurring)
+	require.Equal(t, []string{"2024-05-27"}, got[0].Days())
+}
+
+func TestExpand_CountUntilAndRDate(t *testing.T) {
+	events := parseEvents(t, `BEGIN:VEVENT
+UID:standup
+DTSTART:20250106T090000Z
+DTEND:20250106T091500Z
+RRULE:FREQ=WEEKLY;BYDAY=MO,WE;COUNT=3
+RDATE:20250120T090000Z
+SUMMARY:Standup
+END:VEVENT
+BEGIN:VEVENT
+UID:payday
+DTSTART;VALUE=DATE:20250131
+RRULE:FREQ=MONTHLY;BYMONTHDAY=-1;UNTIL=20250401
+SUMMARY:Payday
+END:VEVENT
+`)
+
+	got := Expand(events, date("2025-01-01"), date("2025-12-31"))
+	require.Equal(t, []string{
+		"2025-01-06 09:00:00", "2025-01-08 09:00:00", "2025-01-13 09:00:00", "2025-01-20 09:00:00",
+		"2025-01-31 00:00:00", "2025-02-28 00:00:00", "2025-03-31 00:00:00",
+	}, starts(got))
+	require.Equal(t, 15*time.Minute, got[1].End.Sub(got[1].Start))
+}
+
+func TestExpand_MultiDayTimezonesAndOverrides(t *testing.T) {
+	events := parseEvents(t, `BEGIN:VEVENT
+UID:closure
+DTSTART;VALUE=DATE:20250804
+DTEND;VALUE=DATE:20250816
+SUMMARY:Summer closure
+END:VEVENT
+BEGIN:VEVENT
+UID:review
+DTSTART;TZID=Europe/Madrid:20250331T100000
+DURATION:PT1H
+RRULE:FREQ=DAILY;INTERVAL=7;COUNT=3
+SUMMARY:Review
+END:VEVENT
+BEGIN:VEVENT
+UID:review
+RECURRENCE-ID;TZID=Europe/Madrid:20250407T100000
+DTSTART;TZID=Europe/Madrid:20250408T120000
+DURATION:PT1H
+SUMMARY:Review (moved)
+END:VEVENT
+`)
+
+	got := Expand(events, date("2025-03-01"), date("2025-09-01"))
+	require.Len(t, got, 4)
+
+	require.Equal(t, "2025-03-31 08:00:00", got[0].Start.UTC().Format(time.DateTime))
+	require.Equal(t, "Europe/Madrid", got[0].Start.Location().String())
+	require.Equal(t, "Review (moved)", got[1].Summary)
+	require.Equal(t, "2025-04-08 12:00:00", got[1].Start.Format(time.DateTime))
+	require.Equal(t, "2025-04-14 10:00:00", got[2].Start.Format(time.DateTime))
+
+	closure := got[3]
+	require.Equal(t, "Summer closure", closure.Summary)
+	days := closure.Days()
+	require.Len(t, days, 12)
+	require.Equal(t, "2025-08-04", days[0])
+	require.Equal(t, "2025-08-15", days[11])
+
+	// the closure overlaps a window that starts in its middle
+	require.Len(t, Expand(events, date("2025-08-10"), date("2025-08-11")), 1)
+}
+
+func TestParseDuration(t *testing.T) {
+	for in, want := range map[string]time.Duration{
+		"P1D":     24 * time.Hour,
+		"PT1H30M": 90 * time.Minute,
+		"P1W":     7 * 24 * time.Hour,
+		"-PT15M":  -15 * time.Minute,
+	} {
+		got, err := parseDuration(in)
+		require.NoError(t, err, in)
+		require.Equal(t, want, got, in)
+	}
+
+	_, err := parseDuration("1 hour")
+	require.Error(t, err)
+}
@@ -0,0 +1,143 @@
+package calendar
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	ics "github.com/arran4/golang-ical"
+)
+
+const (
+	defaultCacheTTL = 6 * time.Hour
+	// retryAfter delays the next refresh when the upstream is down and a stale copy is served.
+	retryAfter   = time.Minute
+	fetchTimeout = 10 * time.Second
+)
+
+// Loader caches parsed calendars by link. Expired calendars are refreshed conditionally and the last
+// good copy is served while the source is failing.
+type Loader struct {
+	ttl    time.Duration
+	now    func() time.Time
+	client *http.Client
+
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+}
+
+type cacheEntry struct {
+	mu         sync.Mutex
+	source     Source
+	events     []*ics.VEvent
+	validators Validators
+	expires    time.Time
+}
+
+// LoaderOption configures a Loader.
+type LoaderOption func(*Loader)
+
+// WithHTTPClient downloads the http(s) calendars through client.
+func WithHTTPClient(client *http.Client) LoaderOption {
+	return func(l *Loader) { l.client = client }
+}
+
+func NewLoader(ttl time.Duration, opts ...LoaderOption) *Loader {
+	if ttl <= 0 {
+		ttl = defaultCacheTTL
+	}
+	l := &Loader{ttl: ttl, now: time.Now, client: &http.Client{Timeout: fetchTimeout}, entries: map[string]*cacheEntry{}}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// LoaderFromEnv reads the cache TTL from CALENDAR_CACHE_TTL, e.g. "12h" (default 6h).
+func LoaderFromEnv(opts ...LoaderOption) *Loader {
+	ttl, _ := time.ParseDuration(os.Getenv("CALENDAR_CACHE_TTL"))
+	return NewLoader(ttl, opts...)
+}
+
+func (l *Loader) entry(link string) (*cacheEntry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e, ok := l.entries[link]
+	if !ok {
+		source, err := NewSource(link, l.client)
+		if err != nil {
+			return nil, err
+		}
+		e = &cacheEntry{source: source}
+		l.entries[link] = e
+	}
+	return e, nil
+}
+
+// Load returns the events of the calendar at link.
+func (l *Loader) Load(ctx context.Context, link string) ([]*ics.VEvent, error) {
+	e, err := l.entry(link)
+	if err != nil {
+		return nil, err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := l.now()
+	if e.events != nil && now.Before(e.expires) {
+		return e.events, nil
+	}
+
+	events, err := l.refresh(ctx, e)
+	if err == nil {
+		e.expires = now.Add(l.ttl)
+		return events, nil
+	}
+	if e.events == nil {
+		return nil, err
+	}
+
+	slog.WarnContext(ctx, "Serving stale calendar", "source", e.source, "error", err)
+	e.expires = now.Add(min(retryAfter, l.ttl))
+	return e.events, nil
+}
+
+func (l *Loader) refresh(ctx context.Context, e *cacheEntry) ([]*ics.VEvent, error) {
+	ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+
+	slog.InfoContext(ctx, "Loading calendar", "source", e.source)
+	data, next, notModified, err := e.source.Fetch(ctx, e.validators)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch calendar %s: %w", e.source, err)
+	}
+	if notModified && e.events != nil {
+		return e.events, nil
+	}
+
+	cal, err := ics.ParseCalendar(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse calendar: %w", err)
+	}
+
+	e.events = cal.Events()
+	e.validators = next
+	return e.events, nil
+}
+
+// Warm loads the calendars in the background so the first tool calls do not wait for them.
+func (l *Loader) Warm(ctx context.Context, links ...string) {
+	for _, link := range links {
+		go func() {
+			if _, err := l.Load(ctx, link); err != nil {
+				slog.WarnContext(ctx, "Failed to warm calendar", "link", link, "error", err)
+			}
+		}()
+	}
+}
@@ -0,0 +1,194 @@
+package calendar
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testICS = `BEGIN:VCALENDAR
+VERSION:2.0
+PRODID:-//Test//EN
+BEGIN:VEVENT
+UID:1
+DTSTART;VALUE=DATE:20250101
+SUMMARY:New Year's Day
+END:VEVENT
+END:VCALENDAR
+`
+
+type clock struct{ t time.Time }
+
+func (c *clock) now() time.Time          { return c.t }
+func (c *clock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func testLoader(ttl time.Duration) (*Loader, *clock) {
+	c := &clock{t: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	l := NewLoader(ttl)
+	l.now = c.now
+	return l, c
+}
+
+func TestLoader_CachesAndRefreshesConditionally(t *testing.T) {
+	var requests, notModified atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(testICS))
+	}))
+	defer srv.Close()
+
+	l, c := testLoader(time.Hour)
+	ctx := context.Background()
+
+	events, err := l.Load(ctx, srv.URL)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+
+	_, err = l.Load(ctx, srv.URL)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, requests.Load(), "served from cache within the TTL")
+
+	c.advance(2 * time.Hour)
+	events, err = l.Load(ctx, srv.URL)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.EqualValues(t, 2, requests.Load())
+	require.EqualValues(t, 1, notModified.Load())
+}
+
+func TestLoader_ServesStaleCopyWhenUpstreamIsDown(t *testing.T) {
+	var down atomic.Bool
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if down.Load() {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(testICS))
+	}))
+	defer srv.Close()
+
+	l, c := testLoader(time.Hour)
+	ctx := context.Background()
+
+	_, err := l.Load(ctx, srv.URL)
+	require.NoError(t, err)
+
+	down.Store(true)
+	c.advance(2 * time.Hour)
+	events, err := l.Load(ctx, srv.URL)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+
+	// the next attempt waits for retryAfter
+	_, _ = l.Load(ctx, srv.URL)
+	require.EqualValues(t, 2, requests.Load())
+
+	c.advance(retryAfter)
+	_, _ = l.Load(ctx, srv.URL)
+	require.EqualValues(t, 3, requests.Load())
+
+	// without a previous copy the error surfaces
+	_, err = NewLoader(time.Hour).Load(ctx, srv.URL)
+	require.ErrorContains(t, err, "503")
+}
+
+func TestLoader_FileAndEmbeddedSources(t *testing.T) {
+	l, c := testLoader(time.Minute)
+	ctx := context.Background()
+
+	path := filepath.Join(t.TempDir(), "holidays.ics")
+	require.NoError(t, os.WriteFile(path, []byte(testICS), 0o644))
+
+	for _, link := range []string{path, "file://" + path} {
+		events, err := l.Load(ctx, link)
+		require.NoError(t, err)
+		require.Equal(t, "New Year's Day", events[0].GetProperty("SUMMARY").Value)
+	}
+
+	// a changed file is read again once expired
+	updated := testICS[:len(testICS)-len("END:VCALENDAR\n")] + "BEGIN:VEVENT\nUID:2\nDTSTART;VALUE=DATE:20250106\nSUMMARY:Epiphany\nEND:VEVENT\nEND:VCALENDAR\n"
+	require.NoError(t, os.WriteFile(path, []byte(updated), 0o644))
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Hour)))
+	c.advance(2 * time.Minute)
+	events, err := l.Load(ctx, path)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+
+	Embed("test-holidays", []byte(testICS))
+	events, err = l.Load(ctx, "embed:test-holidays")
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+
+	_, err = l.Load(ctx, "embed:missing")
+	require.ErrorContains(t, err, `no embedded calendar "missing"`)
+
+	_, err = l.Load(ctx, "ftp://example.com/holidays.ics")
+	require.ErrorContains(t, err, "unsupported calendar source")
+}
+
+func TestLoader_BundledCalendars(t *testing.T) {
+	l, _ := testLoader(time.Hour)
+	ctx := context.Background()
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, region := range DefaultRegions() {
+		events, err := l.Events(ctx, region.Fallback, from, from.AddDate(1, 0, 0))
+		require.NoError(t, err, region.Code)
+		require.NotEmpty(t, events, region.Code)
+	}
+
+	// an unreachable source falls back to the bundled calendar
+	region := DefaultRegions()[DefaultRegion]
+	region.Source = filepath.Join(t.TempDir(), "missing.ics")
+	holidays, err := region.Holidays(ctx, l, from, from.AddDate(1, 0, 0))
+	require.NoError(t, err)
+	require.Equal(t, "National Day of Catalonia", holidays["2026-09-11"])
+}
+
+func TestLoader_RejectsOversizedCalendars(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(make([]byte, maxCalendarSize+1))
+	}))
+	defer srv.Close()
+
+	l, _ := testLoader(time.Hour)
+	_, err := l.Load(context.Background(), srv.URL)
+	require.ErrorContains(t, err, "calendar larger than")
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestLoader_WithHTTPClient(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(testICS))
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		requests.Add(1)
+		return http.DefaultTransport.RoundTrip(r)
+	})}
+
+	events, err := NewLoader(time.Hour, WithHTTPClient(client)).Load(context.Background(), srv.URL)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.EqualValues(t, 1, requests.Load())
+}
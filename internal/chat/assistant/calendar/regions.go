@@ -0,0 +1,182 @@
+package calendar
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+const DefaultRegion = "ES-CT"
+
+// Region is a place with its own public holidays, identified by its ISO 3166 country or ISO 3166-2
+// subdivision code (e.g. "DE" or "ES-CT").
+type Region struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+	// Source is the ICS feed with the holidays of the region.
+	Source string `json:"source"`
+	// Fallback is used when Source cannot be loaded, e.g. the bundled calendar of a default region.
+	Fallback string `json:"fallback,omitempty"`
+	// Weekend lists the non-working weekdays by English name, Saturday and Sunday when empty.
+	Weekend []string `json:"weekend,omitempty"`
+	// WorkingHours is the usual working schedule, e.g. "09:00-18:00".
+	WorkingHours string `json:"working_hours,omitempty"`
+}
+
+// Regions indexes regions by their upper case code.
+type Regions map[string]Region
+
+// DefaultRegions are a few countries with their national holidays plus Catalonia, whose feed can be
+// overridden with HOLIDAY_CALENDAR_LINK. They fall back to the calendars bundled with the binary.
+func DefaultRegions() Regions {
+	link := "https://www.officeholidays.com/ics/spain/catalonia"
+	if v := os.Getenv("HOLIDAY_CALENDAR_LINK"); v != "" {
+		link = v
+	}
+
+	return Regions{
+		DefaultRegion: {Code: DefaultRegion, Name: "Catalonia, Spain", Source: link, Fallback: "embed:es-ct", WorkingHours: "09:00-18:00"},
+		"ES":          {Code: "ES", Name: "Spain", Source: "https://www.officeholidays.com/ics/spain", Fallback: "embed:es"},
+		"DE":          {Code: "DE", Name: "Germany", Source: "https://www.officeholidays.com/ics/germany", Fallback: "embed:de"},
+		"FR":          {Code: "FR", Name: "France", Source: "https://www.officeholidays.com/ics/france", Fallback: "embed:fr"},
+		"IT":          {Code: "IT", Name: "Italy", Source: "https://www.officeholidays.com/ics/italy", Fallback: "embed:it"},
+		"PT":          {Code: "PT", Name: "Portugal", Source: "https://www.officeholidays.com/ics/portugal", Fallback: "embed:pt"},
+		"GB":          {Code: "GB", Name: "United Kingdom", Source: "https://www.officeholidays.com/ics/united-kingdom", Fallback: "embed:gb"},
+		"US":          {Code: "US", Name: "United States", Source: "https://www.officeholidays.com/ics/usa", Fallback: "embed:us"},
+	}
+}
+
+// RegionsFromEnv returns the default regions extended (or overridden) with the JSON list of regions in
+// the file at CALENDAR_REGIONS.
+func RegionsFromEnv() (Regions, error) {
+	regions := DefaultRegions()
+
+	path := os.Getenv("CALENDAR_REGIONS")
+	if path == "" {
+		return regions, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return regions, err
+	}
+
+	var list []Region
+	if err := json.Unmarshal(data, &list); err != nil {
+		return regions, fmt.Errorf("%s: %w", path, err)
+	}
+	for _, r := range list {
+		if r.Code == "" || r.Source == "" {
+			return regions, fmt.Errorf("%s: region code and source are required", path)
+		}
+		r.Code = strings.ToUpper(r.Code)
+		regions[r.Code] = r
+	}
+	return regions, nil
+}
+
+// Lookup finds a region by code, case insensitively. An empty code is the default region.
+func (r Regions) Lookup(code string) (Region, error) {
+	if code == "" {
+		code = DefaultRegion
+	}
+	region, ok := r[strings.ToUpper(strings.TrimSpace(code))]
+	if !ok {
+		return Region{}, fmt.Errorf("unknown region %q, known regions: %s", code, strings.Join(r.Codes(), ", "))
+	}
+	return region, nil
+}
+
+// Resolve finds the region of an ISO 3166-1 country and an optional subdivision, given either as its
+// full ISO 3166-2 code ("DE-BY") or its suffix ("BY"). Unknown subdivisions of a known country fall back
+// to the national holidays, fallback reports it.
+func (r Regions) Resolve(country, subdivision string) (region Region, fallback bool, err error) {
+	country = strings.ToUpper(strings.TrimSpace(country))
+	subdivision = strings.ToUpper(strings.TrimSpace(subdivision))
+
+	code := subdivision
+	if country != "" && subdivision != "" && !strings.HasPrefix(subdivision, country+"-") {
+		code = country + "-" + subdivision
+	}
+	if code == "" {
+		code = country
+	}
+
+	region, err = r.Lookup(code)
+	if err == nil || country == "" || code == country {
+		return region, false, err
+	}
+	if national, ok := r[country]; ok {
+		return national, true, nil
+	}
+	return Region{}, false, err
+}
+
+func (r Regions) Codes() []string {
+	codes := make([]string, 0, len(r))
+	for c := range r {
+		codes = append(codes, c)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// Sources lists the distinct calendar sources of the regions.
+func (r Regions) Sources() []string {
+	var sources []string
+	for _, code := range r.Codes() {
+		if src := r[code].Source; !slices.Contains(sources, src) {
+			sources = append(sources, src)
+		}
+	}
+	return sources
+}
+
+// Label is the name of the region followed by its code.
+func (r Region) Label() string {
+	if r.Name == "" {
+		return r.Code
+	}
+	return fmt.Sprintf("%s (%s)", r.Name, r.Code)
+}
+
+func (r Region) IsWeekend(d time.Weekday) bool {
+	if len(r.Weekend) == 0 {
+		return d == time.Saturday || d == time.Sunday
+	}
+	return slices.ContainsFunc(r.Weekend, func(w string) bool { return strings.EqualFold(w, d.String()) })
+}
+
+// Events loads the holiday events of the region in [from, to) with calendars, from its fallback calendar
+// when the source fails.
+func (r Region) Events(ctx context.Context, calendars *Loader, from, to time.Time) ([]Event, error) {
+	events, err := calendars.Events(ctx, r.Source, from, to)
+	if err != nil && r.Fallback != "" {
+		slog.WarnContext(ctx, "Using fallback holiday calendar", "region", r.Code, "fallback", r.Fallback, "error", err)
+		return calendars.Events(ctx, r.Fallback, from, to)
+	}
+	return events, err
+}
+
+// Holidays loads the holidays of the region in [from, to) with calendars, indexed by the YYYY-MM-DD dates
+// they span.
+func (r Region) Holidays(ctx context.Context, calendars *Loader, from, to time.Time) (map[string]string, error) {
+	events, err := r.Events(ctx, calendars, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]string, len(events))
+	for _, e := range events {
+		for _, day := range e.Days() {
+			out[day] = e.Summary
+		}
+	}
+	return out, nil
+}
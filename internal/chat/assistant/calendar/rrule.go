@@ -0,0 +1,298 @@
+package calendar
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxPeriods bounds the expansion of rules that never match or run for centuries.
+const maxPeriods = 50000
+
+type frequency int
+
+const (
+	daily frequency = iota
+	weekly
+	monthly
+	yearly
+)
+
+type weekdayNum struct {
+	n   int // 0 for every such weekday of the period, negative counts from its end
+	day time.Weekday
+}
+
+// rrule is the subset of RFC 5545 recurrence rules used by holiday and team calendars: daily, weekly,
+// monthly and yearly frequencies with INTERVAL, COUNT, UNTIL, BYMONTH, BYMONTHDAY, BYDAY and WKST.
+type rrule struct {
+	freq       frequency
+	interval   int
+	count      int
+	until      time.Time
+	byMonth    []time.Month
+	byMonthDay []int
+	byDay      []weekdayNum
+	weekStart  time.Weekday
+}
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+func parseRRule(s string, loc *time.Location) (*rrule, error) {
+	r := &rrule{interval: 1, weekStart: time.Monday}
+	hasFreq := false
+
+	for _, part := range strings.Split(s, ";") {
+		key, value, _ := strings.Cut(part, "=")
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			hasFreq = true
+			switch strings.ToUpper(value) {
+			case "DAILY":
+				r.freq = daily
+			case "WEEKLY":
+				r.freq = weekly
+			case "MONTHLY":
+				r.freq = monthly
+			case "YEARLY":
+				r.freq = yearly
+			default:
+				return nil, fmt.Errorf("unsupported frequency %q", value)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid interval %q", value)
+			}
+			r.interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid count %q", value)
+			}
+			r.count = n
+		case "UNTIL":
+			t, _, err := parseTimeValue(value, loc)
+			if err != nil {
+				return nil, fmt.Errorf("invalid until %q: %w", value, err)
+			}
+			r.until = t
+		case "BYMONTH":
+			for _, v := range strings.Split(value, ",") {
+				n, err := strconv.Atoi(v)
+				if err != nil || n < 1 || n > 12 {
+					return nil, fmt.Errorf("invalid month %q", v)
+				}
+				r.byMonth = append(r.byMonth, time.Month(n))
+			}
+		case "BYMONTHDAY":
+			for _, v := range strings.Split(value, ",") {
+				n, err := strconv.Atoi(v)
+				if err != nil || n == 0 || n < -31 || n > 31 {
+					return nil, fmt.Errorf("invalid month day %q", v)
+				}
+				r.byMonthDay = append(r.byMonthDay, n)
+			}
+		case "BYDAY":
+			for _, v := range strings.Split(value, ",") {
+				v = strings.ToUpper(strings.TrimSpace(v))
+				if len(v) < 2 {
+					return nil, fmt.Errorf("invalid weekday %q", v)
+				}
+				day, ok := weekdays[v[len(v)-2:]]
+				if !ok {
+					return nil, fmt.Errorf("invalid weekday %q", v)
+				}
+				n := 0
+				if num := v[:len(v)-2]; num != "" {
+					var err error
+					if n, err = strconv.Atoi(num); err != nil || n == 0 {
+						return nil, fmt.Errorf("invalid weekday %q", v)
+					}
+				}
+				r.byDay = append(r.byDay, weekdayNum{n: n, day: day})
+			}
+		case "WKST":
+			day, ok := weekdays[strings.ToUpper(value)]
+			if !ok {
+				return nil, fmt.Errorf("invalid week start %q", value)
+			}
+			r.weekStart = day
+		default:
+			return nil, fmt.Errorf("unsupported rule part %q", key)
+		}
+	}
+
+	if !hasFreq {
+		return nil, fmt.Errorf("missing frequency")
+	}
+	return r, nil
+}
+
+// occurrences returns the starts of the occurrences of the rule for an event starting at dtstart, up
+// to (excluding) end.
+func (r *rrule) occurrences(dtstart, end time.Time) []time.Time {
+	var out []time.Time
+	n := 0
+	for period := 0; period < maxPeriods; period++ {
+		for _, t := range r.candidates(dtstart, period*r.interval) {
+			if t.Before(dtstart) {
+				continue
+			}
+			if !r.until.IsZero() && t.After(r.until) {
+				return out
+			}
+			if !t.Before(end) {
+				return out
+			}
+			out = append(out, t)
+			if n++; r.count > 0 && n >= r.count {
+				return out
+			}
+		}
+	}
+	return out
+}
+
+// candidates lists, sorted, the occurrences of the rule in the period that is offset frequency units
+// after the one of dtstart.
+func (r *rrule) candidates(dtstart time.Time, offset int) []time.Time {
+	y, m, d := dtstart.Date()
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, dtstart.Hour(), dtstart.Minute(), dtstart.Second(), 0, dtstart.Location())
+	}
+
+	var days []time.Time
+	switch r.freq {
+	case daily:
+		days = []time.Time{at(y, m, d+offset)}
+	case weekly:
+		start := at(y, m, d+7*offset)
+		if len(r.byDay) == 0 {
+			days = []time.Time{start}
+			break
+		}
+		start = start.AddDate(0, 0, -((int(start.Weekday()) - int(r.weekStart) + 7) % 7))
+		for i := range 7 {
+			days = append(days, start.AddDate(0, 0, i))
+		}
+	case monthly:
+		first := at(y, m+time.Month(offset), 1)
+		days = r.monthDays(first, d)
+	case yearly:
+		year := y + offset
+		months := r.byMonth
+		if len(months) == 0 && (len(r.byMonthDay) > 0 || len(r.byDay) > 0) {
+			if len(r.byDay) > 0 && len(r.byMonthDay) == 0 {
+				// BYDAY without BYMONTH counts the weekdays of the whole year
+				days = r.yearDays(at(year, 1, 1))
+				break
+			}
+			months = []time.Month{time.January, time.February, time.March, time.April, time.May, time.June,
+				time.July, time.August, time.September, time.October, time.November, time.December}
+		}
+		if len(months) == 0 {
+			months = []time.Month{m}
+		}
+		for _, month := range months {
+			days = append(days, r.monthDays(at(year, month, 1), d)...)
+		}
+	}
+
+	out := days[:0]
+	for _, t := range days {
+		if r.matches(t) {
+			out = append(out, t)
+		}
+	}
+	slices.SortFunc(out, func(a, b time.Time) int { return a.Compare(b) })
+	return slices.CompactFunc(out, time.Time.Equal)
+}
+
+// monthDays expands the month starting at first by BYMONTHDAY and BYDAY, or to the day of month of
+// dtstart when neither is set.
+func (r *rrule) monthDays(first time.Time, dtstartDay int) []time.Time {
+	last := daysIn(first)
+
+	var days []time.Time
+	switch {
+	case len(r.byMonthDay) > 0:
+		for _, md := range r.byMonthDay {
+			if md < 0 {
+				md = last + md + 1
+			}
+			if md >= 1 && md <= last {
+				days = append(days, first.AddDate(0, 0, md-1))
+			}
+		}
+	case len(r.byDay) > 0:
+		for _, wd := range r.byDay {
+			days = append(days, nthWeekdays(first, last, wd)...)
+		}
+	case dtstartDay <= last:
+		days = []time.Time{first.AddDate(0, 0, dtstartDay-1)}
+	}
+	return days
+}
+
+func (r *rrule) yearDays(first time.Time) []time.Time {
+	length := time.Date(first.Year(), time.December, 31, 0, 0, 0, 0, time.UTC).YearDay()
+
+	var days []time.Time
+	for _, wd := range r.byDay {
+		days = append(days, nthWeekdays(first, length, wd)...)
+	}
+	return days
+}
+
+// nthWeekdays returns the n-th weekday of the span of length days starting at first, or all of them
+// when n is 0.
+func nthWeekdays(first time.Time, length int, wd weekdayNum) []time.Time {
+	var all []time.Time
+	for i := (int(wd.day) - int(first.Weekday()) + 7) % 7; i < length; i += 7 {
+		all = append(all, first.AddDate(0, 0, i))
+	}
+
+	switch {
+	case wd.n == 0:
+		return all
+	case wd.n > 0 && wd.n <= len(all):
+		return all[wd.n-1 : wd.n]
+	case wd.n < 0 && -wd.n <= len(all):
+		return all[len(all)+wd.n : len(all)+wd.n+1]
+	}
+	return nil
+}
+
+// matches applies the BYxxx parts that limit rather than expand the period.
+func (r *rrule) matches(t time.Time) bool {
+	if len(r.byMonth) > 0 && !slices.Contains(r.byMonth, t.Month()) {
+		return false
+	}
+
+	if len(r.byMonthDay) > 0 && (r.freq == daily || r.freq == weekly) {
+		last := daysIn(t)
+		if !slices.ContainsFunc(r.byMonthDay, func(md int) bool { return md == t.Day() || last+md+1 == t.Day() }) {
+			return false
+		}
+	}
+
+	// BYDAY expands weekly periods and, without BYMONTHDAY, monthly and yearly ones too. Otherwise it
+	// only keeps the matching weekdays.
+	limitsByDay := r.freq == daily || r.freq == weekly || len(r.byMonthDay) > 0
+	if len(r.byDay) > 0 && limitsByDay {
+		if !slices.ContainsFunc(r.byDay, func(wd weekdayNum) bool { return wd.day == t.Weekday() }) {
+			return false
+		}
+	}
+	return true
+}
+
+func daysIn(t time.Time) int {
+	return time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
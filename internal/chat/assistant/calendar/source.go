@@ -0,0 +1,170 @@
+package calendar
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const maxCalendarSize = 5 << 20
+
+// bundled holds the holidays of the default regions, served as embed:<code> (e.g. embed:es-ct) when no
+// calendar of that name is registered with Embed.
+//
+//go:embed holidays/*.ics
+var bundled embed.FS
+
+// Validators identify a version of the calendar data so unchanged data is not downloaded again.
+type Validators struct {
+	ETag         string
+	LastModified string
+}
+
+// Source provides the raw ICS data of a calendar.
+type Source interface {
+	// Fetch returns the calendar data and its validators. When prev still matches the current version
+	// it returns nil data and notModified.
+	Fetch(ctx context.Context, prev Validators) (data []byte, next Validators, notModified bool, err error)
+	String() string
+}
+
+// NewSource picks the source of a link: http(s) URLs, downloaded with client, embed:<name> for data
+// registered with Embed or bundled with the binary, and file:// URLs or plain paths for local files.
+// Other schemes are rejected.
+func NewSource(link string, client *http.Client) (Source, error) {
+	switch {
+	case strings.HasPrefix(link, "http://"), strings.HasPrefix(link, "https://"):
+		return httpSource{url: link, client: client}, nil
+	case strings.HasPrefix(link, "embed:"):
+		return embeddedSource{name: strings.TrimPrefix(link, "embed:")}, nil
+	case strings.HasPrefix(link, "file://"):
+		return fileSource{path: strings.TrimPrefix(link, "file://")}, nil
+	}
+
+	// A single letter scheme is a Windows drive.
+	if u, err := url.Parse(link); err == nil && len(u.Scheme) > 1 {
+		return nil, fmt.Errorf("unsupported calendar source %q: use http(s)://, file://, embed: or a path", link)
+	}
+	return fileSource{path: link}, nil
+}
+
+type httpSource struct {
+	url    string
+	client *http.Client
+}
+
+func (s httpSource) String() string { return s.url }
+
+func (s httpSource) Fetch(ctx context.Context, prev Validators) ([]byte, Validators, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, prev, false, err
+	}
+	if prev.ETag != "" {
+		req.Header.Set("If-None-Match", prev.ETag)
+	}
+	if prev.LastModified != "" {
+		req.Header.Set("If-Modified-Since", prev.LastModified)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, prev, false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, prev, true, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, prev, false, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxCalendarSize+1))
+	if err != nil {
+		return nil, prev, false, err
+	}
+	if len(data) > maxCalendarSize {
+		return nil, prev, false, fmt.Errorf("calendar larger than %d bytes", maxCalendarSize)
+	}
+	next := Validators{ETag: resp.Header.Get("ETag"), LastModified: resp.Header.Get("Last-Modified")}
+	return data, next, false, nil
+}
+
+type fileSource struct {
+	path string
+}
+
+func (s fileSource) String() string { return "file://" + s.path }
+
+// Fetch uses the modification time of the file as its validator.
+func (s fileSource) Fetch(_ context.Context, prev Validators) ([]byte, Validators, bool, error) {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return nil, prev, false, err
+	}
+	next := Validators{LastModified: info.ModTime().UTC().Format(time.RFC3339Nano)}
+	if next == prev {
+		return nil, prev, true, nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, prev, false, err
+	}
+	return data, next, false, nil
+}
+
+type embeddedCalendar struct {
+	data    []byte
+	version int
+}
+
+var (
+	embeddedMu sync.RWMutex
+	embedded   = map[string]embeddedCalendar{}
+)
+
+// Embed registers ICS data, e.g. a file bundled with go:embed, as the calendar source embed:<name>.
+func Embed(name string, data []byte) {
+	embeddedMu.Lock()
+	defer embeddedMu.Unlock()
+	embedded[name] = embeddedCalendar{data: data, version: embedded[name].version + 1}
+}
+
+type embeddedSource struct {
+	name string
+}
+
+func (s embeddedSource) String() string { return "embed:" + s.name }
+
+func (s embeddedSource) Fetch(_ context.Context, prev Validators) ([]byte, Validators, bool, error) {
+	embeddedMu.RLock()
+	cal, ok := embedded[s.name]
+	embeddedMu.RUnlock()
+	if !ok {
+		data, err := bundled.ReadFile("holidays/" + s.name + ".ics")
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, prev, false, fmt.Errorf("no embedded calendar %q", s.name)
+		}
+		if err != nil {
+			return nil, prev, false, err
+		}
+		cal = embeddedCalendar{data: data}
+	}
+
+	next := Validators{ETag: fmt.Sprintf("%q-%d", s.name, cal.version)}
+	if next == prev {
+		return nil, prev, true, nil
+	}
+	return cal.data, next, false, nil
+}
@@ -0,0 +1,91 @@
+package assistant
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/assistant/tools"
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/model"
+	"github.com/openai/openai-go/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const rejectedToolResult = "The user rejected this action, it was not executed. Do not try it again unless the user asks for it."
+
+// pause stores the reply on conv.PendingAction until the user approves or rejects the calls, and
+// returns the message asking for the approval.
+func (a *Assistant) pause(ctx context.Context, conv *model.Conversation, msgs []openai.ChatCompletionMessageParamUnion, calls []openai.ChatCompletionMessageToolCallUnion) (string, error) {
+	transcript, err := json.Marshal(msgs)
+	if err != nil {
+		return "", fmt.Errorf("failed to store pending action: %w", err)
+	}
+
+	action := &model.PendingAction{
+		ID:         primitive.NewObjectID(),
+		Transcript: string(transcript),
+		CreatedAt:  time.Now(),
+	}
+
+	var b strings.Builder
+	b.WriteString("I need your approval before I continue. I would like to run:\n")
+	for _, call := range calls {
+		action.ToolCalls = append(action.ToolCalls, model.ToolCall{
+			ID:        call.ID,
+			Name:      call.Function.Name,
+			Arguments: call.Function.Arguments,
+		})
+		fmt.Fprintf(&b, "- %s %s\n", call.Function.Name, call.Function.Arguments)
+	}
+	b.WriteString("Do you approve?")
+
+	slog.InfoContext(ctx, "Reply paused for user approval", "conversation_id", conv.ID, "action_id", action.ID, "tools", len(calls))
+
+	conv.PendingAction = action
+	return b.String(), nil
+}
+
+// Resume continues the reply paused on conv.PendingAction, running the pending tool calls if the user
+// approved them or telling the model they were rejected otherwise. The pending action is cleared, a
+// new one may be set if the resumed reply needs another approval.
+func (a *Assistant) Resume(ctx context.Context, conv *model.Conversation, approve bool) (string, error) {
+	action := conv.PendingAction
+	if action == nil {
+		return "", errors.New("conversation has no pending action")
+	}
+
+	var msgs []openai.ChatCompletionMessageParamUnion
+	if err := json.Unmarshal([]byte(action.Transcript), &msgs); err != nil {
+		return "", fmt.Errorf("invalid pending action transcript: %w", err)
+	}
+
+	slog.InfoContext(ctx, "Resuming reply", "conversation_id", conv.ID, "action_id", action.ID, "approved", approve)
+	ctx = tools.WithConversation(ctx, conv.ID.Hex())
+	ctx = tools.WithPreferences(ctx, conv.Preferences)
+	conv.PendingAction = nil
+
+	calls := make([]openai.ChatCompletionMessageToolCallUnion, 0, len(action.ToolCalls))
+	for _, c := range action.ToolCalls {
+		calls = append(calls, openai.ChatCompletionMessageToolCallUnion{
+			ID:       c.ID,
+			Type:     "function",
+			Function: openai.ChatCompletionMessageFunctionToolCallFunction{Name: c.Name, Arguments: c.Arguments},
+		})
+	}
+
+	if approve {
+		toolCtx, cancel := context.WithTimeout(ctx, a.limits.Budget)
+		msgs = append(msgs, a.runTools(toolCtx, a.tools, calls)...)
+		cancel()
+	} else {
+		for _, call := range calls {
+			msgs = append(msgs, openai.ToolMessage(rejectedToolResult, call.ID))
+		}
+	}
+
+	return a.run(ctx, conv, msgs)
+}
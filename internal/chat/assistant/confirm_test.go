@@ -0,0 +1,119 @@
+package assistant_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/assistant"
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/assistant/tools"
+	"github.com/stretchr/testify/require"
+)
+
+// bookingServer asks for the weather and a booking, then answers with the tool results it was given.
+func bookingServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Messages []struct {
+				Role    string `json:"role"`
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		w.Header().Set("Content-Type", "application/json")
+
+		if body.Messages[len(body.Messages)-1].Role == "user" {
+			b, _ := json.Marshal(map[string]any{
+				"id": "chatcmpl-book", "object": "chat.completion", "created": 1730000000, "model": "test-model",
+				"choices": []any{map[string]any{
+					"index": 0, "finish_reason": "tool_calls",
+					"message": map[string]any{"role": "assistant", "content": nil, "tool_calls": []any{
+						map[string]any{"id": "call_a", "type": "function", "function": map[string]any{"name": "sleepy", "arguments": `{"city":"Porto"}`}},
+						map[string]any{"id": "call_b", "type": "function", "function": map[string]any{"name": "book_table", "arguments": `{"people":2}`}},
+					}},
+				}},
+			})
+			_, _ = w.Write(b)
+			return
+		}
+
+		var results []string
+		for _, m := range body.Messages {
+			if m.Role == "tool" {
+				results = append(results, m.Content)
+			}
+		}
+		b, _ := json.Marshal(map[string]any{
+			"id": "chatcmpl-done", "object": "chat.completion", "created": 1730000000, "model": "test-model",
+			"choices": []any{map[string]any{
+				"index": 0, "finish_reason": "stop",
+				"message": map[string]any{"role": "assistant", "content": strings.Join(results, " | ")},
+			}},
+		})
+		_, _ = w.Write(b)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func bookingAssistant(t *testing.T, bookings *atomic.Int32) *assistant.Assistant {
+	type bookArgs struct {
+		People int `json:"people" required:"true" minimum:"1"`
+	}
+	book := tools.Typed("book_table", "Books a table", func(ctx context.Context, args bookArgs) (string, error) {
+		bookings.Add(1)
+		return fmt.Sprintf("table booked for %d", args.People), nil
+	}).WithConfirmation()
+
+	return assistant.NewWithConfig(assistant.Config{
+		Providers: []assistant.Provider{testProvider("primary", bookingServer(t).URL)},
+		Retry:     fastRetry,
+		Tools:     tools.NewRegistry(&sleepyTool{delay: time.Millisecond}, book),
+	})
+}
+
+func TestAssistant_Reply_PausesForConfirmation(t *testing.T) {
+	var bookings atomic.Int32
+	a := bookingAssistant(t, &bookings)
+	conv := testConversation()
+
+	reply, err := a.Reply(context.Background(), conv)
+	require.NoError(t, err)
+	require.Contains(t, reply, `book_table {"people":2}`)
+	require.Zero(t, bookings.Load(), "the booking must wait for the approval")
+
+	require.NotNil(t, conv.PendingAction)
+	require.Len(t, conv.PendingAction.ToolCalls, 1)
+	require.Equal(t, "book_table", conv.PendingAction.ToolCalls[0].Name)
+
+	reply, err = a.Resume(context.Background(), conv, true)
+	require.NoError(t, err)
+	require.Equal(t, "weather in Porto | table booked for 2", reply)
+	require.EqualValues(t, 1, bookings.Load())
+	require.Nil(t, conv.PendingAction)
+
+	_, err = a.Resume(context.Background(), conv, true)
+	require.Error(t, err, "the action can only be resolved once")
+}
+
+func TestAssistant_Resume_RejectedActionIsNotRun(t *testing.T) {
+	var bookings atomic.Int32
+	a := bookingAssistant(t, &bookings)
+	conv := testConversation()
+
+	_, err := a.Reply(context.Background(), conv)
+	require.NoError(t, err)
+
+	reply, err := a.Resume(context.Background(), conv, false)
+	require.NoError(t, err)
+	require.Contains(t, reply, "The user rejected this action")
+	require.Zero(t, bookings.Load())
+	require.Nil(t, conv.PendingAction)
+}
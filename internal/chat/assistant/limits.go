@@ -0,0 +1,126 @@
+package assistant
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/assistant/tools"
+	"github.com/openai/openai-go/v2"
+)
+
+// Limits bound the work done by a single Reply. When one is reached the assistant stops calling tools
+// and answers with what it has gathered so far.
+type Limits struct {
+	// MaxIterations is the maximum number of completions that may request tool calls.
+	MaxIterations int
+	// MaxCallsPerTool is the maximum number of calls to the same tool.
+	MaxCallsPerTool int
+	// MaxIdenticalCalls is the maximum number of calls to the same tool with the same arguments.
+	MaxIdenticalCalls int
+	// Budget is the total wall clock time available to gather tool results.
+	Budget time.Duration
+}
+
+func DefaultLimits() Limits {
+	return Limits{
+		MaxIterations:     15,
+		MaxCallsPerTool:   6,
+		MaxIdenticalCalls: 2,
+		Budget:            90 * time.Second,
+	}
+}
+
+func LimitsFromEnv() Limits {
+	l := DefaultLimits()
+	if v, err := strconv.Atoi(os.Getenv("ASSISTANT_MAX_ITERATIONS")); err == nil && v > 0 {
+		l.MaxIterations = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("ASSISTANT_MAX_CALLS_PER_TOOL")); err == nil && v > 0 {
+		l.MaxCallsPerTool = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("ASSISTANT_MAX_IDENTICAL_CALLS")); err == nil && v > 0 {
+		l.MaxIdenticalCalls = v
+	}
+	if v, err := time.ParseDuration(os.Getenv("ASSISTANT_REPLY_BUDGET")); err == nil && v > 0 {
+		l.Budget = v
+	}
+	return l
+}
+
+// ToolTimeoutsFromEnv parses ASSISTANT_TOOL_TIMEOUTS, e.g. "get_weather=5s,get_holidays=20s".
+func ToolTimeoutsFromEnv() map[string]time.Duration {
+	out := map[string]time.Duration{}
+	for _, entry := range strings.Split(os.Getenv("ASSISTANT_TOOL_TIMEOUTS"), ",") {
+		name, v, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok {
+			continue
+		}
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			out[name] = d
+		}
+	}
+	return out
+}
+
+// replyBudget tracks the usage of a single Reply against the configured limits.
+type replyBudget struct {
+	limits    Limits
+	deadline  time.Time
+	iteration int
+	perTool   map[string]int
+	identical map[string]int
+}
+
+func newReplyBudget(l Limits) *replyBudget {
+	return &replyBudget{
+		limits:    l,
+		deadline:  time.Now().Add(l.Budget),
+		perTool:   map[string]int{},
+		identical: map[string]int{},
+	}
+}
+
+// next accounts for a new completion, it returns the reason to stop if a limit has been reached.
+func (b *replyBudget) next() (string, bool) {
+	if b.iteration >= b.limits.MaxIterations {
+		return fmt.Sprintf("reached the maximum of %d tool rounds", b.limits.MaxIterations), false
+	}
+	if time.Now().After(b.deadline) {
+		return fmt.Sprintf("ran out of the %s time budget", b.limits.Budget), false
+	}
+	b.iteration++
+	return "", true
+}
+
+// admit accounts for the tool calls of a completion, it returns the reason to stop if running them
+// would exceed a limit. Calls are only counted when all of them are admitted.
+func (b *replyBudget) admit(calls []openai.ChatCompletionMessageToolCallUnion) (string, bool) {
+	perTool := map[string]int{}
+	identical := map[string]int{}
+
+	for _, call := range calls {
+		name := call.Function.Name
+		key := tools.CacheKey(name, call.Function.Arguments)
+
+		perTool[name]++
+		identical[key]++
+
+		if b.perTool[name]+perTool[name] > b.limits.MaxCallsPerTool {
+			return fmt.Sprintf("tool %s was called more than %d times", name, b.limits.MaxCallsPerTool), false
+		}
+		if b.identical[key]+identical[key] > b.limits.MaxIdenticalCalls {
+			return fmt.Sprintf("tool %s was called repeatedly with the same arguments %s", name, call.Function.Arguments), false
+		}
+	}
+
+	for k, v := range perTool {
+		b.perTool[k] += v
+	}
+	for k, v := range identical {
+		b.identical[k] += v
+	}
+	return "", true
+}
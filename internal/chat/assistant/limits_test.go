@@ -0,0 +1,131 @@
+package assistant_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/assistant"
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/assistant/tools"
+	"github.com/stretchr/testify/require"
+)
+
+const finalAnswerJSON = `{
+	"id": "chatcmpl-final",
+	"object": "chat.completion",
+	"created": 1730000000,
+	"model": "test-model",
+	"choices": [{
+		"index": 0,
+		"finish_reason": "stop",
+		"message": {"role": "assistant", "content": "Here is what I found so far."}
+	}]
+}`
+
+// loopingServer keeps asking for tool calls until the request forbids them.
+func loopingServer(t *testing.T, hits *atomic.Int32, city func(n int) string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(hits.Add(1))
+		w.Header().Set("Content-Type", "application/json")
+
+		var body struct {
+			ToolChoice string `json:"tool_choice"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body.ToolChoice == "none" {
+			_, _ = io.WriteString(w, finalAnswerJSON)
+			return
+		}
+		_, _ = io.WriteString(w, toolCallsJSON(city(n)))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestAssistant_Reply_StopsIdenticalToolCallLoops(t *testing.T) {
+	var hits atomic.Int32
+	srv := loopingServer(t, &hits, func(int) string { return "Porto" })
+
+	tool := &countingTool{}
+	a := assistant.NewWithConfig(assistant.Config{
+		Providers: []assistant.Provider{testProvider("primary", srv.URL)},
+		Retry:     fastRetry,
+		Tools:     tools.NewRegistry(tool),
+		Limits: assistant.Limits{
+			MaxIterations:     10,
+			MaxCallsPerTool:   10,
+			MaxIdenticalCalls: 2,
+			Budget:            time.Minute,
+		},
+	})
+
+	reply, err := a.Reply(context.Background(), testConversation())
+	require.NoError(t, err)
+	require.Equal(t, "Here is what I found so far.", reply)
+	require.EqualValues(t, 2, tool.calls.Load())
+	require.EqualValues(t, 4, hits.Load(), "two tool rounds, the rejected one and the final answer")
+}
+
+func TestAssistant_Reply_StopsAtMaxCallsPerTool(t *testing.T) {
+	var hits atomic.Int32
+	srv := loopingServer(t, &hits, func(n int) string { return "City " + strconv.Itoa(n) })
+
+	tool := &countingTool{}
+	a := assistant.NewWithConfig(assistant.Config{
+		Providers: []assistant.Provider{testProvider("primary", srv.URL)},
+		Retry:     fastRetry,
+		Tools:     tools.NewRegistry(tool),
+		Limits: assistant.Limits{
+			MaxIterations:     10,
+			MaxCallsPerTool:   3,
+			MaxIdenticalCalls: 2,
+			Budget:            time.Minute,
+		},
+	})
+
+	reply, err := a.Reply(context.Background(), testConversation())
+	require.NoError(t, err)
+	require.Equal(t, "Here is what I found so far.", reply)
+	require.EqualValues(t, 3, tool.calls.Load())
+}
+
+func TestAssistant_Reply_StopsAtMaxIterations(t *testing.T) {
+	var hits atomic.Int32
+	srv := loopingServer(t, &hits, func(n int) string { return "City " + strconv.Itoa(n) })
+
+	tool := &countingTool{}
+	a := assistant.NewWithConfig(assistant.Config{
+		Providers: []assistant.Provider{testProvider("primary", srv.URL)},
+		Retry:     fastRetry,
+		Tools:     tools.NewRegistry(tool),
+		Limits: assistant.Limits{
+			MaxIterations:     2,
+			MaxCallsPerTool:   10,
+			MaxIdenticalCalls: 2,
+			Budget:            time.Minute,
+		},
+	})
+
+	reply, err := a.Reply(context.Background(), testConversation())
+	require.NoError(t, err)
+	require.Equal(t, "Here is what I found so far.", reply)
+	require.EqualValues(t, 2, tool.calls.Load())
+	require.EqualValues(t, 3, hits.Load())
+}
+
+type countingTool struct {
+	sleepyTool
+	calls atomic.Int32
+}
+
+func (c *countingTool) Call(ctx context.Context, rawArgs string) (string, error) {
+	c.calls.Add(1)
+	return c.sleepyTool.Call(ctx, rawArgs)
+}
@@ -0,0 +1,177 @@
+package assistant
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/openai/openai-go/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type llmMetrics struct {
+	Attempts metric.Int64Counter
+	Duration metric.Float64Histogram
+	Tracer   trace.Tracer
+}
+
+func newLLMMetrics() *llmMetrics {
+	meter := otel.Meter("acai/assistant")
+
+	attempts, err := meter.Int64Counter("assistant.llm.attempts",
+		metric.WithDescription("Number of chat completion attempts per provider and outcome"),
+	)
+	if err != nil {
+		slog.Error("Failed to create assistant.llm.attempts counter", "error", err)
+	}
+
+	duration, err := meter.Float64Histogram("assistant.llm.duration.seconds",
+		metric.WithDescription("Duration of chat completion attempts (s)"),
+	)
+	if err != nil {
+		slog.Error("Failed to create assistant.llm.duration.seconds histogram", "error", err)
+	}
+
+	return &llmMetrics{
+		Attempts: attempts,
+		Duration: duration,
+		Tracer:   otel.Tracer("acai/assistant"),
+	}
+}
+
+// complete runs a chat completion, retrying each provider with backoff and falling back to the next
+// provider in order once the retries are exhausted. The model in params is overridden per provider.
+func (a *Assistant) complete(ctx context.Context, params openai.ChatCompletionNewParams) (*openai.ChatCompletion, error) {
+	if len(a.providers) == 0 {
+		return nil, errors.New("no LLM providers configured")
+	}
+
+	ctx, span := a.metrics.Tracer.Start(ctx, "llm.chat_completion")
+	defer span.End()
+
+	var errs []error
+	for _, p := range a.providers {
+		resp, err := a.completeWith(ctx, p, params)
+		if err == nil {
+			span.SetAttributes(
+				attribute.String("llm.provider", p.Name),
+				attribute.String("llm.model", p.Model),
+			)
+			return resp, nil
+		}
+
+		errs = append(errs, fmt.Errorf("%s/%s: %w", p.Name, p.Model, err))
+		if ctx.Err() != nil {
+			break
+		}
+
+		slog.WarnContext(ctx, "LLM provider failed, trying next one", "provider", p.Name, "model", p.Model, "error", err)
+	}
+
+	err := errors.Join(errs...)
+	span.RecordError(err)
+	span.SetStatus(codes.Error, "all LLM providers failed")
+	return nil, err
+}
+
+func (a *Assistant) completeWith(ctx context.Context, p Provider, params openai.ChatCompletionNewParams) (*openai.ChatCompletion, error) {
+	params.Model = p.Model
+
+	attempts := max(a.retry.MaxAttempts, 1)
+	for attempt := 0; ; attempt++ {
+		resp, err := a.attempt(ctx, p, params, attempt)
+		if err == nil {
+			return resp, nil
+		}
+
+		if !isRetryable(err) || attempt+1 >= attempts {
+			return nil, err
+		}
+
+		delay := a.retry.backoff(attempt)
+		if ra, ok := retryAfter(err); ok {
+			if ra > a.retry.MaxDelay {
+				// the provider asks us to wait longer than we are willing to, move on to the next one
+				return nil, err
+			}
+			delay = ra
+		}
+
+		slog.WarnContext(ctx, "LLM request failed, retrying", "provider", p.Name, "model", p.Model, "attempt", attempt+1, "delay", delay, "error", err)
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (a *Assistant) attempt(ctx context.Context, p Provider, params openai.ChatCompletionNewParams, attempt int) (*openai.ChatCompletion, error) {
+	attrs := []attribute.KeyValue{
+		attribute.String("llm.provider", p.Name),
+		attribute.String("llm.model", p.Model),
+		attribute.Int("llm.attempt", attempt+1),
+	}
+
+	ctx, span := a.metrics.Tracer.Start(ctx, "llm.attempt", trace.WithAttributes(attrs...))
+	defer span.End()
+
+	start := time.Now()
+	resp, err := p.Client.Chat.Completions.New(ctx, params)
+	elapsed := time.Since(start).Seconds()
+
+	outcome := "success"
+	if err != nil {
+		outcome = "error"
+		if isRetryable(err) {
+			outcome = "retryable_error"
+		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	if code := statusCode(err); code != 0 {
+		span.SetAttributes(attribute.Int("http.status_code", code))
+	}
+
+	metricAttrs := metric.WithAttributes(
+		attribute.String("llm.provider", p.Name),
+		attribute.String("llm.model", p.Model),
+		attribute.String("llm.outcome", outcome),
+	)
+	a.metrics.Attempts.Add(ctx, 1, metricAttrs)
+	a.metrics.Duration.Record(ctx, elapsed, metricAttrs)
+
+	return resp, err
+}
+
+// completeJSON runs a completion constrained to the given JSON schema and decodes the answer into out.
+func (a *Assistant) completeJSON(ctx context.Context, msgs []openai.ChatCompletionMessageParamUnion, name string, schema map[string]any, out any) error {
+	resp, err := a.complete(ctx, openai.ChatCompletionNewParams{
+		Messages: msgs,
+		ResponseFormat: openai.ChatCompletionNewParamsResponseFormatUnion{
+			OfJSONSchema: &openai.ResponseFormatJSONSchemaParam{
+				JSONSchema: openai.ResponseFormatJSONSchemaJSONSchemaParam{
+					Name:   name,
+					Strict: openai.Bool(true),
+					Schema: schema,
+				},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if len(resp.Choices) == 0 || resp.Choices[0].Message.Content == "" {
+		return fmt.Errorf("empty %s response from OpenAI", name)
+	}
+
+	if err := json.Unmarshal([]byte(resp.Choices[0].Message.Content), out); err != nil {
+		return fmt.Errorf("invalid %s response from OpenAI: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package assistant_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/assistant"
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/model"
+	"github.com/openai/openai-go/v2/option"
+	"github.com/stretchr/testify/require"
+)
+
+const completionJSON = `{
+	"id": "chatcmpl-test",
+	"object": "chat.completion",
+	"created": 1730000000,
+	"model": "test-model",
+	"choices": [{
+		"index": 0,
+		"finish_reason": "stop",
+		"message": {"role": "assistant", "content": "Weather in Barcelona"}
+	}]
+}`
+
+// llmServer answers with the given status codes in order and with a completion afterwards.
+func llmServer(t *testing.T, hits *atomic.Int32, statuses ...int) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(hits.Add(1))
+		w.Header().Set("Content-Type", "application/json")
+		if n <= len(statuses) {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(statuses[n-1])
+			_, _ = w.Write([]byte(`{"error":{"message":"try again","type":"server_error"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(completionJSON))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func testProvider(name, url string) assistant.Provider {
+	return assistant.NewProvider(name, "test-model", option.WithBaseURL(url), option.WithAPIKey("test"))
+}
+
+func testConversation() *model.Conversation {
+	return &model.Conversation{
+		Messages: []*model.Message{{Role: model.RoleUser, Content: "What is the weather like in Barcelona?"}},
+	}
+}
+
+var fastRetry = assistant.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond}
+
+func TestAssistant_RetriesRateLimitedRequests(t *testing.T) {
+	var hits atomic.Int32
+	srv := llmServer(t, &hits, http.StatusTooManyRequests, http.StatusBadGateway)
+
+	a := assistant.NewWithConfig(assistant.Config{
+		Providers: []assistant.Provider{testProvider("primary", srv.URL)},
+		Retry:     fastRetry,
+	})
+
+	title, err := a.Title(context.Background(), testConversation())
+	require.NoError(t, err)
+	require.Equal(t, "Weather in Barcelona", title)
+	require.EqualValues(t, 3, hits.Load())
+}
+
+func TestAssistant_FallsBackWhenPrimaryKeepsFailing(t *testing.T) {
+	var primaryHits, fallbackHits atomic.Int32
+	primary := llmServer(t, &primaryHits, http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+	fallback := llmServer(t, &fallbackHits)
+
+	a := assistant.NewWithConfig(assistant.Config{
+		Providers: []assistant.Provider{testProvider("primary", primary.URL), testProvider("fallback", fallback.URL)},
+		Retry:     fastRetry,
+	})
+
+	title, err := a.Title(context.Background(), testConversation())
+	require.NoError(t, err)
+	require.Equal(t, "Weather in Barcelona", title)
+	require.EqualValues(t, 3, primaryHits.Load())
+	require.EqualValues(t, 1, fallbackHits.Load())
+}
+
+func TestAssistant_DoesNotRetryClientErrors(t *testing.T) {
+	var primaryHits, fallbackHits atomic.Int32
+	primary := llmServer(t, &primaryHits, http.StatusBadRequest)
+	fallback := llmServer(t, &fallbackHits, http.StatusBadRequest)
+
+	a := assistant.NewWithConfig(assistant.Config{
+		Providers: []assistant.Provider{testProvider("primary", primary.URL), testProvider("fallback", fallback.URL)},
+		Retry:     fastRetry,
+	})
+
+	_, err := a.Title(context.Background(), testConversation())
+	require.Error(t, err)
+	require.EqualValues(t, 1, primaryHits.Load())
+	require.EqualValues(t, 1, fallbackHits.Load())
+}
@@ -0,0 +1,80 @@
+package assistant_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/assistant"
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/assistant/tools"
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/model"
+	"github.com/stretchr/testify/require"
+)
+
+const todayCallJSON = `{
+	"id": "chatcmpl-tools",
+	"object": "chat.completion",
+	"created": 1730000000,
+	"model": "test-model",
+	"choices": [{
+		"index": 0,
+		"finish_reason": "tool_calls",
+		"message": {"role": "assistant", "content": null, "tool_calls": [
+			{"id": "call_a", "type": "function", "function": {"name": "get_today_date", "arguments": "{}"}}
+		]}
+	}]
+}`
+
+func TestAssistant_Reply_FollowsPreferences(t *testing.T) {
+	var hits atomic.Int32
+	var system, tool []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if hits.Add(1) == 1 {
+			_, _ = io.WriteString(w, todayCallJSON)
+			return
+		}
+
+		var body struct {
+			Messages []struct {
+				Role    string `json:"role"`
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		for _, m := range body.Messages {
+			switch m.Role {
+			case "system":
+				system = append(system, m.Content)
+			case "tool":
+				tool = append(tool, m.Content)
+			}
+		}
+		_, _ = io.WriteString(w, completionJSON)
+	}))
+	defer srv.Close()
+
+	a := assistant.NewWithConfig(assistant.Config{
+		Providers: []assistant.Provider{testProvider("primary", srv.URL)},
+		Retry:     fastRetry,
+		Tools:     tools.NewRegistry(tools.NewTodayTool()),
+	})
+
+	conv := testConversation()
+	conv.Preferences = &model.Preferences{Locale: "en-US", Timezone: "America/New_York"}
+	_, err := a.Reply(context.Background(), conv)
+	require.NoError(t, err)
+
+	require.Len(t, system, 2)
+	require.Contains(t, system[1], "imperial units")
+	require.Contains(t, system[1], "12/31/2025 6:30 PM")
+	require.Contains(t, system[1], "America/New_York")
+
+	require.Len(t, tool, 1)
+	require.Contains(t, tool[0], "America/New_York")
+	require.Regexp(t, `[-+]0[45]:00 `, tool[0])
+}
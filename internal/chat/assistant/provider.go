@@ -0,0 +1,78 @@
+package assistant
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/option"
+)
+
+const defaultModel = openai.ChatModelGPT4_1
+
+// Provider is an OpenAI compatible chat completion backend together with the model to use on it.
+type Provider struct {
+	Name   string
+	Model  openai.ChatModel
+	Client openai.Client
+}
+
+// NewProvider builds a provider with SDK level retries disabled, retries are handled by the assistant.
+func NewProvider(name string, model openai.ChatModel, opts ...option.RequestOption) Provider {
+	opts = append(opts[:len(opts):len(opts)], option.WithMaxRetries(0))
+	return Provider{Name: name, Model: model, Client: openai.NewClient(opts...)}
+}
+
+// ProvidersFromEnv returns the primary OpenAI provider (OPENAI_MODEL) followed by the fallbacks in
+// ASSISTANT_FALLBACKS, a comma separated list of "provider:model" entries, e.g.
+// "openai:gpt-4.1-mini,groq:llama-3.3-70b-versatile". opts are applied to every provider.
+func ProvidersFromEnv(opts ...option.RequestOption) []Provider {
+	model := defaultModel
+	if v := os.Getenv("OPENAI_MODEL"); v != "" {
+		model = v
+	}
+
+	providers := []Provider{NewProvider("openai", model, opts...)}
+
+	for _, entry := range strings.Split(os.Getenv("ASSISTANT_FALLBACKS"), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		p, err := ParseProvider(entry, opts...)
+		if err != nil {
+			slog.Warn("Ignoring invalid assistant fallback", "entry", entry, "error", err)
+			continue
+		}
+		providers = append(providers, p)
+	}
+
+	return providers
+}
+
+// ParseProvider builds a provider from a "provider:model" spec. Providers other than openai are
+// configured with <PROVIDER>_BASE_URL and <PROVIDER>_API_KEY.
+func ParseProvider(spec string, opts ...option.RequestOption) (Provider, error) {
+	name, model, ok := strings.Cut(strings.TrimSpace(spec), ":")
+	if !ok || name == "" || model == "" {
+		return Provider{}, fmt.Errorf("invalid provider %q, expected provider:model", spec)
+	}
+
+	if name == "openai" {
+		return NewProvider(name, model, opts...), nil
+	}
+
+	prefix := strings.ToUpper(name)
+	baseURL := os.Getenv(prefix + "_BASE_URL")
+	if baseURL == "" {
+		return Provider{}, fmt.Errorf("provider %s requires %s_BASE_URL", name, prefix)
+	}
+
+	return NewProvider(name, model, append([]option.RequestOption{
+		option.WithBaseURL(baseURL),
+		option.WithAPIKey(os.Getenv(prefix + "_API_KEY")),
+	}, opts...)...), nil
+}
@@ -0,0 +1,66 @@
+package assistant
+
+import (
+	"context"
+	"strings"
+
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/model"
+	"github.com/openai/openai-go/v2"
+)
+
+const maxSuggestions = 3
+
+var suggestionsSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"suggestions": map[string]any{
+			"type":        "array",
+			"description": "Between 0 and 3 follow-up questions",
+			"items":       map[string]any{"type": "string"},
+		},
+	},
+	"required":             []string{"suggestions"},
+	"additionalProperties": false,
+}
+
+// Suggest proposes up to three follow-up questions the user may want to ask after the last reply.
+func (a *Assistant) Suggest(ctx context.Context, conv *model.Conversation) ([]string, error) {
+	if len(conv.Messages) == 0 {
+		return nil, nil
+	}
+
+	msgs := []openai.ChatCompletionMessageParamUnion{
+		openai.SystemMessage(
+			"You suggest follow-up questions for a travel assistant chat. Based on the conversation, propose 0 to 3 short " +
+				"follow-up questions (max 60 characters each) that the USER would naturally click next, written in the user's voice " +
+				"and language, e.g. \"Show the 5-day forecast\". Only suggest things the assistant can answer. " +
+				"Return an empty list when no follow-up makes sense.",
+		),
+	}
+	for _, m := range conv.Messages {
+		switch m.Role {
+		case model.RoleUser:
+			msgs = append(msgs, openai.UserMessage(m.Content))
+		case model.RoleAssistant:
+			msgs = append(msgs, openai.AssistantMessage(m.Content))
+		}
+	}
+
+	var out struct {
+		Suggestions []string `json:"suggestions"`
+	}
+	if err := a.completeJSON(ctx, msgs, "follow_up_suggestions", suggestionsSchema, &out); err != nil {
+		return nil, err
+	}
+
+	var suggestions []string
+	for _, s := range out.Suggestions {
+		if s = strings.TrimSpace(s); s != "" {
+			suggestions = append(suggestions, s)
+		}
+		if len(suggestions) == maxSuggestions {
+			break
+		}
+	}
+	return suggestions, nil
+}
@@ -0,0 +1,76 @@
+package assistant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/model"
+	"github.com/openai/openai-go/v2"
+)
+
+var summarySchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"summary": map[string]any{
+			"type":        "string",
+			"description": "TL;DR of the conversation in at most 3 sentences",
+		},
+		"key_decisions": map[string]any{
+			"type":        "array",
+			"description": "Decisions taken or conclusions reached in the conversation",
+			"items":       map[string]any{"type": "string"},
+		},
+		"action_items": map[string]any{
+			"type":        "array",
+			"description": "Pending tasks or next steps for the user",
+			"items":       map[string]any{"type": "string"},
+		},
+	},
+	"required":             []string{"summary", "key_decisions", "action_items"},
+	"additionalProperties": false,
+}
+
+// Summarize produces a short summary of the conversation with its key decisions and action items.
+func (a *Assistant) Summarize(ctx context.Context, conv *model.Conversation) (*model.Summary, error) {
+	if len(conv.Messages) == 0 {
+		return nil, errors.New("conversation has no messages")
+	}
+
+	slog.InfoContext(ctx, "Generating summary for conversation", "conversation_id", conv.ID)
+
+	var transcript strings.Builder
+	for _, m := range conv.Messages {
+		fmt.Fprintf(&transcript, "%s: %s\n\n", strings.ToUpper(string(m.Role)), m.Content)
+	}
+
+	msgs := []openai.ChatCompletionMessageParamUnion{
+		openai.SystemMessage(
+			"You summarize conversations between a user and a travel assistant for a busy manager. " +
+				"Write a TL;DR of at most 3 sentences, list the key decisions taken and the action items left for the user. " +
+				"Be factual, do not invent anything that is not in the conversation and use empty lists when there is nothing to report. " +
+				"Answer in the language of the conversation.",
+		),
+		openai.UserMessage("Conversation:\n\n" + transcript.String()),
+	}
+
+	var out struct {
+		Summary      string   `json:"summary"`
+		KeyDecisions []string `json:"key_decisions"`
+		ActionItems  []string `json:"action_items"`
+	}
+	if err := a.completeJSON(ctx, msgs, "conversation_summary", summarySchema, &out); err != nil {
+		return nil, err
+	}
+
+	return &model.Summary{
+		Summary:      strings.TrimSpace(out.Summary),
+		KeyDecisions: out.KeyDecisions,
+		ActionItems:  out.ActionItems,
+		MessageCount: len(conv.Messages),
+		CreatedAt:    time.Now(),
+	}, nil
+}
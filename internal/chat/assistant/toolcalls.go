@@ -0,0 +1,86 @@
+package assistant
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/assistant/tools"
+	"github.com/openai/openai-go/v2"
+)
+
+const (
+	defaultToolConcurrency = 4
+	defaultToolTimeout     = 15 * time.Second
+)
+
+// runTools executes the tool calls of a single completion concurrently, at most a.toolConcurrency at a
+// time, and returns the tool messages in the same order as the calls.
+func (a *Assistant) runTools(ctx context.Context, reg *tools.Registry, calls []openai.ChatCompletionMessageToolCallUnion) []openai.ChatCompletionMessageParamUnion {
+	out := make([]openai.ChatCompletionMessageParamUnion, len(calls))
+	sem := make(chan struct{}, max(a.toolConcurrency, 1))
+
+	var wg sync.WaitGroup
+	for i, call := range calls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				out[i] = openai.ToolMessage("tool error: "+ctx.Err().Error(), call.ID)
+				return
+			}
+
+			out[i] = openai.ToolMessage(a.runTool(ctx, reg, call), call.ID)
+		}()
+	}
+	wg.Wait()
+
+	return out
+}
+
+func (a *Assistant) runTool(ctx context.Context, reg *tools.Registry, call openai.ChatCompletionMessageToolCallUnion) string {
+	slog.InfoContext(ctx, "Tool call received", "name", call.Function.Name, "args", call.Function.Arguments)
+
+	t, ok := reg.Get(call.Function.Name)
+	if !ok {
+		return "unknown tool: " + call.Function.Name
+	}
+
+	timeout := a.toolTimeout
+	if d, ok := a.toolTimeouts[call.Function.Name]; ok {
+		timeout = d
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type result struct {
+		payload string
+		err     error
+	}
+
+	// tools are expected to honor ctx, but a misbehaving one must not hold the reply hostage
+	done := make(chan result, 1)
+	go func() {
+		payload, err := t.Call(ctx, call.Function.Arguments)
+		done <- result{payload: payload, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		if r.err != nil {
+			slog.WarnContext(ctx, "Tool call failed", "name", call.Function.Name, "error", r.err)
+			return "tool error: " + r.err.Error()
+		}
+		return r.payload
+	case <-ctx.Done():
+		slog.WarnContext(ctx, "Tool call timed out", "name", call.Function.Name, "timeout", timeout)
+		return fmt.Sprintf("tool error: %s did not respond in time", call.Function.Name)
+	}
+}
@@ -0,0 +1,131 @@
+package assistant_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/assistant"
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/assistant/tools"
+	"github.com/openai/openai-go/v2"
+	"github.com/stretchr/testify/require"
+)
+
+type sleepyTool struct {
+	delay   time.Duration
+	running atomic.Int32
+	peak    atomic.Int32
+}
+
+func (*sleepyTool) Name() string        { return "sleepy" }
+func (*sleepyTool) Description() string { return "Echoes the city after a while" }
+func (*sleepyTool) Parameters() openai.FunctionParameters {
+	return openai.FunctionParameters{"type": "object", "properties": map[string]any{"city": map[string]any{"type": "string"}}}
+}
+
+func (s *sleepyTool) Call(ctx context.Context, rawArgs string) (string, error) {
+	n := s.running.Add(1)
+	defer s.running.Add(-1)
+	for {
+		p := s.peak.Load()
+		if n <= p || s.peak.CompareAndSwap(p, n) {
+			break
+		}
+	}
+
+	var args struct {
+		City string `json:"city"`
+	}
+	_ = json.Unmarshal([]byte(rawArgs), &args)
+
+	delay := s.delay
+	if args.City == "Slowtown" {
+		delay = time.Minute
+	}
+
+	select {
+	case <-time.After(delay):
+		return "weather in " + args.City, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+func toolCallsJSON(cities ...string) string {
+	var calls []map[string]any
+	for i, c := range cities {
+		calls = append(calls, map[string]any{
+			"id":       "call_" + string(rune('a'+i)),
+			"type":     "function",
+			"function": map[string]any{"name": "sleepy", "arguments": `{"city":"` + c + `"}`},
+		})
+	}
+	b, _ := json.Marshal(map[string]any{
+		"id": "chatcmpl-tools", "object": "chat.completion", "created": 1730000000, "model": "test-model",
+		"choices": []any{map[string]any{
+			"index": 0, "finish_reason": "tool_calls",
+			"message": map[string]any{"role": "assistant", "content": nil, "tool_calls": calls},
+		}},
+	})
+	return string(b)
+}
+
+func TestAssistant_Reply_RunsToolCallsConcurrentlyInOrder(t *testing.T) {
+	var toolMessages []string
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if hits.Add(1) == 1 {
+			_, _ = io.WriteString(w, toolCallsJSON("Porto", "Seville", "Valencia", "Slowtown"))
+			return
+		}
+
+		var body struct {
+			Messages []struct {
+				Role       string `json:"role"`
+				Content    any    `json:"content"`
+				ToolCallID string `json:"tool_call_id"`
+			} `json:"messages"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		for _, m := range body.Messages {
+			if m.Role == "tool" {
+				b, _ := json.Marshal(m.Content)
+				toolMessages = append(toolMessages, m.ToolCallID+" "+string(b))
+			}
+		}
+		_, _ = io.WriteString(w, completionJSON)
+	}))
+	defer srv.Close()
+
+	tool := &sleepyTool{delay: 200 * time.Millisecond}
+	a := assistant.NewWithConfig(assistant.Config{
+		Providers:       []assistant.Provider{testProvider("primary", srv.URL)},
+		Retry:           fastRetry,
+		Tools:           tools.NewRegistry(tool),
+		ToolConcurrency: 3,
+		ToolTimeout:     300 * time.Millisecond,
+	})
+
+	start := time.Now()
+	reply, err := a.Reply(context.Background(), testConversation())
+	require.NoError(t, err)
+	require.Equal(t, "Weather in Barcelona", reply)
+
+	// three 200ms calls in parallel plus the 300ms timeout of the slow one, far from running them in sequence
+	require.Less(t, time.Since(start), 900*time.Millisecond)
+	require.EqualValues(t, 3, tool.peak.Load())
+
+	require.Len(t, toolMessages, 4)
+	require.Contains(t, toolMessages[0], "call_a")
+	require.Contains(t, toolMessages[0], "weather in Porto")
+	require.Contains(t, toolMessages[1], "weather in Seville")
+	require.Contains(t, toolMessages[2], "weather in Valencia")
+	require.Contains(t, toolMessages[3], "call_d")
+	require.Contains(t, toolMessages[3], "did not respond")
+}
@@ -0,0 +1,197 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/assistant/calendar"
+)
+
+const maxBusinessDaysRange = 5 * 366
+
+type businessDaysArgs struct {
+	Operation string `json:"operation" required:"true" enum:"between,add,is_working_day" description:"between: count the business days from date to end_date (both included); add: the date that is days business days after date (before when negative); is_working_day: whether date is a working day."`
+	Date      string `json:"date" required:"true" description:"Date as YYYY-MM-DD."`
+	EndDate   string `json:"end_date,omitempty" description:"End date as YYYY-MM-DD, required by between."`
+	Days      int    `json:"days,omitempty" description:"Business days to add, required by add." minimum:"-1000" maximum:"1000"`
+	Region    string `json:"region,omitempty" description:"ISO 3166-2 code of the region whose holidays apply, e.g. ES-CT (default)."`
+}
+
+// NewBusinessDaysTool computes working days from the weekend rules and the holiday calendar of regions,
+// loaded with calendars.
+func NewBusinessDaysTool(regions calendar.Regions, calendars *calendar.Loader) Tool {
+	return Typed("business_days",
+		"Business day calculator: counts working days between two dates, adds N working days to a date or checks whether a date is a working day, skipping weekends and public holidays of the region.",
+		func(ctx context.Context, args businessDaysArgs) (string, error) {
+			return businessDays(ctx, regions, calendars, args)
+		}).
+		WithCacheTTL(6 * time.Hour)
+}
+
+func businessDays(ctx context.Context, regions calendar.Regions, calendars *calendar.Loader, args businessDaysArgs) (string, error) {
+	region, err := regions.Lookup(args.Region)
+	if err != nil {
+		return "", err
+	}
+
+	date, err := time.Parse(time.DateOnly, args.Date)
+	if err != nil {
+		return "", fmt.Errorf("invalid date %q, expected YYYY-MM-DD", args.Date)
+	}
+
+	// the holidays are loaded for the days the operation may walk through
+	from, to := date, date.AddDate(0, 0, 1)
+	var end time.Time
+	switch args.Operation {
+	case "between":
+		if end, err = time.Parse(time.DateOnly, args.EndDate); err != nil {
+			return "", fmt.Errorf("invalid end_date %q, expected YYYY-MM-DD", args.EndDate)
+		}
+		from, to = minTime(date, end), maxTime(date, end).AddDate(0, 0, 1)
+	case "add":
+		// weekends and holidays rarely take more than half of the days
+		span := 2*abs(args.Days) + 30
+		from, to = date.AddDate(0, 0, -span), date.AddDate(0, 0, span)
+	}
+
+	holidays, err := region.Holidays(ctx, calendars, from, to)
+	if err != nil {
+		return "", fmt.Errorf("failed to load holidays of %s: %w", region.Code, err)
+	}
+	wc := workCalendar{region: region, holidays: holidays}
+
+	switch args.Operation {
+	case "between":
+		return wc.between(date, end)
+	case "add":
+		return wc.add(date, args.Days), nil
+	default:
+		return wc.isWorkingDay(date), nil
+	}
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+type workCalendar struct {
+	region   calendar.Region
+	holidays map[string]string
+}
+
+// closed returns why d is not a working day, or "" when it is one.
+func (c workCalendar) closed(d time.Time) string {
+	if name, ok := c.holidays[d.Format(time.DateOnly)]; ok {
+		return name
+	}
+	if c.region.IsWeekend(d.Weekday()) {
+		return "weekend"
+	}
+	return ""
+}
+
+func (c workCalendar) between(start, end time.Time) (string, error) {
+	if end.Before(start) {
+		start, end = end, start
+	}
+	if end.Sub(start) > maxBusinessDaysRange*24*time.Hour {
+		return "", fmt.Errorf("date range too long, at most %d days are supported", maxBusinessDaysRange)
+	}
+
+	count := 0
+	var skipped []string
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		switch reason := c.closed(d); reason {
+		case "":
+			count++
+		case "weekend":
+		default:
+			skipped = append(skipped, d.Format(time.DateOnly)+" "+reason)
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s between %s and %s in %s (both dates included).",
+		businessDaysCount(count), start.Format(time.DateOnly), end.Format(time.DateOnly), c.region.Label())
+	writeSkipped(&b, skipped)
+	return b.String(), nil
+}
+
+func (c workCalendar) add(start time.Time, days int) string {
+	step := 1
+	if days < 0 {
+		step = -1
+	}
+
+	d := start
+	var skipped []string
+	for remaining := days * step; remaining > 0; {
+		d = d.AddDate(0, 0, step)
+		switch reason := c.closed(d); reason {
+		case "":
+			remaining--
+		case "weekend":
+		default:
+			skipped = append(skipped, d.Format(time.DateOnly)+" "+reason)
+		}
+	}
+
+	var b strings.Builder
+	direction := "after"
+	if days < 0 {
+		direction = "before"
+	}
+	fmt.Fprintf(&b, "%s %s %s in %s is %s (%s).",
+		businessDaysCount(days*step), direction, start.Format(time.DateOnly), c.region.Label(), d.Format(time.DateOnly), d.Weekday())
+	writeSkipped(&b, skipped)
+	return b.String()
+}
+
+func (c workCalendar) isWorkingDay(d time.Time) string {
+	day := fmt.Sprintf("%s (%s)", d.Format(time.DateOnly), d.Weekday())
+
+	switch reason := c.closed(d); reason {
+	case "":
+		s := fmt.Sprintf("%s is a working day in %s.", day, c.region.Label())
+		if c.region.WorkingHours != "" {
+			s += " Usual working hours: " + c.region.WorkingHours + "."
+		}
+		return s
+	case "weekend":
+		return fmt.Sprintf("%s is not a working day in %s: weekend.", day, c.region.Label())
+	default:
+		return fmt.Sprintf("%s is not a working day in %s: public holiday, %s.", day, c.region.Label(), reason)
+	}
+}
+
+func businessDaysCount(n int) string {
+	if n == 1 {
+		return "1 business day"
+	}
+	return fmt.Sprintf("%d business days", n)
+}
+
+func writeSkipped(b *strings.Builder, skipped []string) {
+	if len(skipped) > 0 {
+		b.WriteString(" Holidays skipped: " + strings.Join(skipped, ", ") + ".")
+	}
+}
@@ -0,0 +1,87 @@
+package tools_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/assistant/calendar"
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/assistant/tools"
+	"github.com/stretchr/testify/require"
+)
+
+func businessDaysTool(t *testing.T) tools.Tool {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/calendar")
+		_, _ = w.Write([]byte(sampleICS))
+	}))
+	t.Cleanup(srv.Close)
+
+	return tools.NewBusinessDaysTool(calendar.Regions{
+		"ES-CT": {Code: "ES-CT", Name: "Catalonia, Spain", Source: srv.URL, WorkingHours: "09:00-18:00"},
+		"IL":    {Code: "IL", Name: "Israel", Source: srv.URL, Weekend: []string{"Friday", "Saturday"}},
+	}, calendar.NewLoader(0))
+}
+
+func TestBusinessDaysTool_Between(t *testing.T) {
+	bt := businessDaysTool(t)
+
+	out, err := bt.Call(context.Background(), `{"operation":"between","date":"2025-01-01","end_date":"2025-01-10"}`)
+	require.NoError(t, err)
+	require.Contains(t, out, "6 business days between 2025-01-01 and 2025-01-10 in Catalonia, Spain (ES-CT)")
+	require.Contains(t, out, "2025-01-06 Epiphany")
+
+	// reversed dates are swapped
+	out, err = bt.Call(context.Background(), `{"operation":"between","date":"2025-01-10","end_date":"2025-01-01"}`)
+	require.NoError(t, err)
+	require.Contains(t, out, "6 business days between 2025-01-01 and 2025-01-10")
+
+	// Sunday is a working day in Israel
+	out, err = bt.Call(context.Background(), `{"operation":"between","date":"2025-01-02","end_date":"2025-01-05","region":"il"}`)
+	require.NoError(t, err)
+	require.Contains(t, out, "2 business days")
+}
+
+func TestBusinessDaysTool_Add(t *testing.T) {
+	bt := businessDaysTool(t)
+
+	out, err := bt.Call(context.Background(), `{"operation":"add","date":"2025-01-03","days":2}`)
+	require.NoError(t, err)
+	require.Contains(t, out, "2 business days after 2025-01-03 in Catalonia, Spain (ES-CT) is 2025-01-08 (Wednesday)")
+	require.Contains(t, out, "Epiphany")
+
+	out, err = bt.Call(context.Background(), `{"operation":"add","date":"2025-01-07","days":-1}`)
+	require.NoError(t, err)
+	require.Contains(t, out, "1 business day before 2025-01-07 in Catalonia, Spain (ES-CT) is 2025-01-03 (Friday)")
+}
+
+func TestBusinessDaysTool_IsWorkingDay(t *testing.T) {
+	bt := businessDaysTool(t)
+
+	out, err := bt.Call(context.Background(), `{"operation":"is_working_day","date":"2025-01-07"}`)
+	require.NoError(t, err)
+	require.Contains(t, out, "is a working day")
+	require.Contains(t, out, "09:00-18:00")
+
+	out, err = bt.Call(context.Background(), `{"operation":"is_working_day","date":"2025-01-06"}`)
+	require.NoError(t, err)
+	require.Contains(t, out, "not a working day in Catalonia, Spain (ES-CT): public holiday, Epiphany")
+
+	out, err = bt.Call(context.Background(), `{"operation":"is_working_day","date":"2025-01-04"}`)
+	require.NoError(t, err)
+	require.Contains(t, out, "weekend")
+}
+
+func TestBusinessDaysTool_Errors(t *testing.T) {
+	bt := businessDaysTool(t)
+
+	_, err := bt.Call(context.Background(), `{"operation":"is_working_day","date":"2025-01-07","region":"FR"}`)
+	require.ErrorContains(t, err, `unknown region "FR", known regions: ES-CT, IL`)
+
+	_, err = bt.Call(context.Background(), `{"operation":"between","date":"2025-01-07"}`)
+	require.ErrorContains(t, err, "invalid end_date")
+
+	_, err = tools.Validated(bt).Call(context.Background(), `{"operation":"count","date":"2025-01-07"}`)
+	require.Error(t, err)
+}
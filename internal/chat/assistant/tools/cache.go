@@ -0,0 +1,138 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Cacheable is implemented by tools whose results can be reused for identical arguments.
+type Cacheable interface {
+	CacheTTL() time.Duration
+}
+
+type CacheStore interface {
+	Get(ctx context.Context, key string) (string, bool, error)
+	Set(ctx context.Context, key string, value string, ttl time.Duration) error
+}
+
+type cacheMetrics struct {
+	Hits   metric.Int64Counter
+	Misses metric.Int64Counter
+}
+
+var sharedCacheMetrics = sync.OnceValue(newCacheMetrics)
+
+func newCacheMetrics() *cacheMetrics {
+	meter := otel.Meter("acai/tools")
+
+	hits, err := meter.Int64Counter("tools.cache.hits",
+		metric.WithDescription("Number of tool calls served from the cache"),
+	)
+	if err != nil {
+		slog.Error("Failed to create tools.cache.hits counter", "error", err)
+	}
+
+	misses, err := meter.Int64Counter("tools.cache.misses",
+		metric.WithDescription("Number of cacheable tool calls not found in the cache"),
+	)
+	if err != nil {
+		slog.Error("Failed to create tools.cache.misses counter", "error", err)
+	}
+
+	return &cacheMetrics{Hits: hits, Misses: misses}
+}
+
+type cachedTool struct {
+	Tool
+	ttl     time.Duration
+	store   CacheStore
+	metrics *cacheMetrics
+}
+
+// Cached wraps t so that successful results are stored in store for the TTL declared by the tool. Results
+// are only reused on the day they were computed, since tools resolve "today" or "next" against the clock.
+// Tools that do not implement Cacheable (or declare no TTL) are returned as they are.
+func Cached(t Tool, store CacheStore) Tool {
+	c, ok := t.(Cacheable)
+	if !ok || c.CacheTTL() <= 0 || store == nil {
+		return t
+	}
+	return &cachedTool{Tool: t, ttl: c.CacheTTL(), store: store, metrics: sharedCacheMetrics()}
+}
+
+func (c *cachedTool) Unwrap() Tool { return c.Tool }
+
+func (c *cachedTool) Call(ctx context.Context, rawArgs string) (string, error) {
+	key := CacheKey(c.Name(), rawArgs) + "|" + time.Now().Format(time.DateOnly)
+	if p := PreferencesFrom(ctx); p != nil {
+		// results are formatted for the user
+		key += "|" + p.Units + "|" + p.Locale + "|" + p.Timezone
+	}
+	attrs := metric.WithAttributes(attribute.String("tool.name", c.Name()))
+
+	if v, ok, err := c.store.Get(ctx, key); err != nil {
+		slog.WarnContext(ctx, "Tool cache lookup failed", "tool", c.Name(), "error", err)
+	} else if ok {
+		c.metrics.Hits.Add(ctx, 1, attrs)
+		return v, nil
+	}
+	c.metrics.Misses.Add(ctx, 1, attrs)
+
+	out, err := c.Tool.Call(ctx, rawArgs)
+	if err != nil {
+		return "", err
+	}
+
+	if err := c.store.Set(ctx, key, out, c.ttl); err != nil {
+		slog.WarnContext(ctx, "Tool cache store failed", "tool", c.Name(), "error", err)
+	}
+	return out, nil
+}
+
+// CacheKey identifies a tool call by tool name and normalized arguments.
+func CacheKey(name, rawArgs string) string {
+	return name + ":" + NormalizeArgs(rawArgs)
+}
+
+// NormalizeArgs makes semantically equal JSON arguments compare equal: key order, whitespace and
+// surrounding spaces in string values are ignored, and empty arguments are the same as {}.
+func NormalizeArgs(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "{}"
+	}
+
+	var v any
+	if err := json.Unmarshal([]byte(raw), &v); err != nil {
+		return raw
+	}
+	b, err := json.Marshal(trimStrings(v))
+	if err != nil {
+		return raw
+	}
+	return string(b)
+}
+
+func trimStrings(v any) any {
+	switch v := v.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case map[string]any:
+		for k, e := range v {
+			v[k] = trimStrings(e)
+		}
+	case []any:
+		for i, e := range v {
+			v[i] = trimStrings(e)
+		}
+	}
+	return v
+}
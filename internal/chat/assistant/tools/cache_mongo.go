@@ -0,0 +1,62 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const toolCacheCollection = "tool_cache"
+
+// MongoCache is a CacheStore shared by all server instances, expired entries are removed by a TTL index.
+type MongoCache struct {
+	conn *mongo.Database
+}
+
+type mongoCacheEntry struct {
+	Key       string    `bson:"_id"`
+	Value     string    `bson:"value"`
+	ExpiresAt time.Time `bson:"expires_at"`
+}
+
+func NewMongoCache(conn *mongo.Database) *MongoCache {
+	return &MongoCache{conn: conn}
+}
+
+func (c *MongoCache) EnsureIndexes(ctx context.Context) error {
+	_, err := c.conn.Collection(toolCacheCollection).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	return err
+}
+
+func (c *MongoCache) Get(ctx context.Context, key string) (string, bool, error) {
+	var e mongoCacheEntry
+
+	err := c.conn.Collection(toolCacheCollection).FindOne(ctx, bson.M{
+		"_id":        key,
+		"expires_at": bson.M{"$gt": time.Now()},
+	}).Decode(&e)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+
+	return e.Value, true, nil
+}
+
+func (c *MongoCache) Set(ctx context.Context, key string, value string, ttl time.Duration) error {
+	_, err := c.conn.Collection(toolCacheCollection).ReplaceOne(ctx,
+		bson.M{"_id": key},
+		mongoCacheEntry{Key: key, Value: value, ExpiresAt: time.Now().Add(ttl)},
+		options.Replace().SetUpsert(true),
+	)
+	return err
+}
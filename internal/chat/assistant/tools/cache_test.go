@@ -0,0 +1,125 @@
+package tools_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/assistant/tools"
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/model"
+	"github.com/openai/openai-go/v2"
+	"github.com/stretchr/testify/require"
+)
+
+type countingTool struct {
+	calls int
+	ttl   time.Duration
+	err   error
+}
+
+func (*countingTool) Name() string                          { return "counting" }
+func (*countingTool) Description() string                   { return "Counts its calls" }
+func (*countingTool) Parameters() openai.FunctionParameters { return openai.FunctionParameters{} }
+func (c *countingTool) CacheTTL() time.Duration             { return c.ttl }
+
+func (c *countingTool) Call(ctx context.Context, rawArgs string) (string, error) {
+	c.calls++
+	if c.err != nil {
+		return "", c.err
+	}
+	return "result for " + rawArgs, nil
+}
+
+func TestRegistry_EnableCache_ReusesResultsForNormalizedArgs(t *testing.T) {
+	ctx := context.Background()
+	tool := &countingTool{ttl: time.Minute}
+
+	reg := tools.NewRegistry(tool)
+	reg.EnableCache(tools.NewLRUCache(10))
+
+	first, err := reg.Execute(ctx, "counting", `{"location":"Porto","days":3}`)
+	require.NoError(t, err)
+
+	second, err := reg.Execute(ctx, "counting", `{ "days": 3, "location": " Porto " }`)
+	require.NoError(t, err)
+	require.Equal(t, first, second)
+	require.Equal(t, 1, tool.calls)
+
+	_, err = reg.Execute(ctx, "counting", `{"location":"Lisbon","days":3}`)
+	require.NoError(t, err)
+	require.Equal(t, 2, tool.calls)
+
+	// results formatted for other preferences are not shared
+	imperial := tools.WithPreferences(ctx, &model.Preferences{Units: model.UnitsImperial})
+	for range 2 {
+		_, err = reg.Execute(imperial, "counting", `{"location":"Lisbon","days":3}`)
+		require.NoError(t, err)
+	}
+	require.Equal(t, 3, tool.calls)
+}
+
+type keyRecorder struct {
+	*tools.LRUCache
+	keys []string
+}
+
+func (r *keyRecorder) Set(ctx context.Context, key string, value string, ttl time.Duration) error {
+	r.keys = append(r.keys, key)
+	return r.LRUCache.Set(ctx, key, value, ttl)
+}
+
+func TestRegistry_EnableCache_KeysResultsByDay(t *testing.T) {
+	store := &keyRecorder{LRUCache: tools.NewLRUCache(10)}
+	reg := tools.NewRegistry(&countingTool{ttl: time.Hour})
+	reg.EnableCache(store)
+
+	_, err := reg.Execute(context.Background(), "counting", `{}`)
+	require.NoError(t, err)
+	require.Len(t, store.keys, 1)
+	require.Contains(t, store.keys[0], time.Now().Format(time.DateOnly), "results must not outlive the day they were computed on")
+}
+
+func TestRegistry_EnableCache_SkipsErrorsAndUncacheableTools(t *testing.T) {
+	ctx := context.Background()
+
+	failing := &countingTool{ttl: time.Minute, err: errors.New("boom")}
+	reg := tools.NewRegistry(failing)
+	reg.EnableCache(tools.NewLRUCache(10))
+
+	for range 2 {
+		_, err := reg.Execute(ctx, "counting", `{}`)
+		require.Error(t, err)
+	}
+	require.Equal(t, 2, failing.calls)
+
+	uncached := &countingTool{}
+	reg = tools.NewRegistry(uncached)
+	reg.EnableCache(tools.NewLRUCache(10))
+
+	for range 2 {
+		_, err := reg.Execute(ctx, "counting", `{}`)
+		require.NoError(t, err)
+	}
+	require.Equal(t, 2, uncached.calls)
+}
+
+func TestLRUCache_EvictsAndExpires(t *testing.T) {
+	ctx := context.Background()
+	c := tools.NewLRUCache(2)
+
+	require.NoError(t, c.Set(ctx, "a", "1", time.Minute))
+	require.NoError(t, c.Set(ctx, "b", "2", time.Minute))
+
+	_, ok, _ := c.Get(ctx, "a") // a becomes the most recently used
+	require.True(t, ok)
+
+	require.NoError(t, c.Set(ctx, "c", "3", time.Minute))
+	_, ok, _ = c.Get(ctx, "b")
+	require.False(t, ok, "b should have been evicted")
+	require.Equal(t, 2, c.Len())
+
+	require.NoError(t, c.Set(ctx, "d", "4", -time.Second))
+	_, ok, _ = c.Get(ctx, "d")
+	require.False(t, ok, "expired entries should not be returned")
+}
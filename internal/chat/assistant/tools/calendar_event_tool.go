@@ -0,0 +1,120 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CalendarEventStore keeps the events created with create_calendar_event, see model.Repository.
+type CalendarEventStore interface {
+	CreateCalendarEvent(ctx context.Context, e *model.CalendarEvent) error
+	CalendarFeedToken(ctx context.Context, conversationID primitive.ObjectID) (string, error)
+}
+
+type calendarEventArgs struct {
+	Title       string `json:"title" required:"true" description:"Short title, e.g. 'Flight VY1234 BCN → LHR'."`
+	Start       string `json:"start" required:"true" description:"Start as an RFC3339 date-time, a local date-time YYYY-MM-DDTHH:MM in timezone, or YYYY-MM-DD for all-day events."`
+	End         string `json:"end,omitempty" description:"Optional end in the same format as start. For all-day events it is the last day of the event. Defaults to one hour after start, or a single day."`
+	Timezone    string `json:"timezone,omitempty" description:"Optional IANA time zone of local date-times, e.g. Europe/Madrid. Defaults to UTC."`
+	Location    string `json:"location,omitempty" description:"Optional place or address."`
+	Description string `json:"description,omitempty" description:"Optional notes, e.g. booking references."`
+}
+
+// NewCalendarEventTool adds events to the calendar of the conversation, which is served as an ICS feed
+// under feedBaseURL/calendars/{conversation_id}.ics?token={token}.
+func NewCalendarEventTool(store CalendarEventStore, feedBaseURL string) Tool {
+	return Typed("create_calendar_event",
+		"Adds an event (flight, meeting, reservation...) to the user's calendar for this conversation. The user can subscribe to the calendar with the returned URL.",
+		func(ctx context.Context, args calendarEventArgs) (string, error) {
+			return createCalendarEvent(ctx, store, strings.TrimSuffix(feedBaseURL, "/"), args)
+		}).
+		WithConfirmation()
+}
+
+func createCalendarEvent(ctx context.Context, store CalendarEventStore, feedBaseURL string, args calendarEventArgs) (string, error) {
+	conversationID, err := primitive.ObjectIDFromHex(ConversationID(ctx))
+	if err != nil {
+		return "", errors.New("calendar events can only be created within a conversation")
+	}
+
+	loc := time.UTC
+	if args.Timezone != "" {
+		if loc, err = time.LoadLocation(args.Timezone); err != nil {
+			return "", fmt.Errorf("unknown timezone %q", args.Timezone)
+		}
+	}
+
+	start, allDay, err := parseEventTime(args.Start, loc)
+	if err != nil {
+		return "", fmt.Errorf("invalid start: %w", err)
+	}
+
+	var end time.Time
+	switch {
+	case args.End != "":
+		var endAllDay bool
+		if end, endAllDay, err = parseEventTime(args.End, loc); err != nil {
+			return "", fmt.Errorf("invalid end: %w", err)
+		}
+		if endAllDay != allDay {
+			return "", errors.New("start and end must both be dates or both be date-times")
+		}
+		if allDay {
+			end = end.AddDate(0, 0, 1)
+		}
+		if !end.After(start) {
+			return "", errors.New("end must be after start")
+		}
+	case allDay:
+		end = start.AddDate(0, 0, 1)
+	default:
+		end = start.Add(time.Hour)
+	}
+
+	event := &model.CalendarEvent{
+		ID:             primitive.NewObjectID(),
+		ConversationID: conversationID,
+		Title:          args.Title,
+		Description:    args.Description,
+		Location:       args.Location,
+		Start:          start,
+		End:            end,
+		AllDay:         allDay,
+		CreatedAt:      time.Now(),
+	}
+	if err := store.CreateCalendarEvent(ctx, event); err != nil {
+		return "", fmt.Errorf("failed to save calendar event: %w", err)
+	}
+	token, err := store.CalendarFeedToken(ctx, conversationID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get calendar feed token: %w", err)
+	}
+
+	when := start.Format(time.RFC3339) + " to " + end.Format(time.RFC3339)
+	if allDay {
+		when = start.Format(time.DateOnly) + " to " + end.AddDate(0, 0, -1).Format(time.DateOnly)
+	}
+	return fmt.Sprintf("Added %q (%s) to the calendar. Subscribe to it at %s/calendars/%s.ics?token=%s",
+		args.Title, when, feedBaseURL, conversationID.Hex(), token), nil
+}
+
+func parseEventTime(s string, loc *time.Location) (time.Time, bool, error) {
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, true, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, false, nil
+	}
+	for _, layout := range []string{"2006-01-02T15:04:05", "2006-01-02T15:04"} {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, false, nil
+		}
+	}
+	return time.Time{}, false, fmt.Errorf("%q is not a date or date-time", s)
+}
@@ -0,0 +1,80 @@
+package tools_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/assistant/tools"
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/model"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type eventStore struct {
+	events []*model.CalendarEvent
+}
+
+func (s *eventStore) CreateCalendarEvent(ctx context.Context, e *model.CalendarEvent) error {
+	s.events = append(s.events, e)
+	return nil
+}
+
+func (s *eventStore) CalendarFeedToken(ctx context.Context, conversationID primitive.ObjectID) (string, error) {
+	return "secret-" + conversationID.Hex(), nil
+}
+
+func TestCalendarEventTool(t *testing.T) {
+	store := &eventStore{}
+	tool := tools.NewCalendarEventTool(store, "https://acai.example.com/")
+	require.True(t, tool.(tools.Confirmable).RequiresConfirmation())
+
+	conv := primitive.NewObjectID()
+	ctx := tools.WithConversation(context.Background(), conv.Hex())
+
+	out, err := tool.Call(ctx, `{"title":"Flight VY1234","start":"2025-10-03T07:30","end":"2025-10-03T09:45","timezone":"Europe/Madrid","location":"BCN"}`)
+	require.NoError(t, err)
+	require.Contains(t, out, `Added "Flight VY1234" (2025-10-03T07:30:00+02:00 to 2025-10-03T09:45:00+02:00)`)
+	require.Contains(t, out, "https://acai.example.com/calendars/"+conv.Hex()+".ics?token=secret-"+conv.Hex())
+
+	require.Len(t, store.events, 1)
+	e := store.events[0]
+	require.Equal(t, conv, e.ConversationID)
+	require.Equal(t, time.Date(2025, 10, 3, 5, 30, 0, 0, time.UTC), e.Start.UTC())
+	require.False(t, e.AllDay)
+
+	// all-day events end the day after their last one
+	out, err = tool.Call(ctx, `{"title":"London trip","start":"2025-10-03","end":"2025-10-05"}`)
+	require.NoError(t, err)
+	require.Contains(t, out, "(2025-10-03 to 2025-10-05)")
+	require.True(t, store.events[1].AllDay)
+	require.Equal(t, time.Date(2025, 10, 6, 0, 0, 0, 0, time.UTC), store.events[1].End)
+
+	// the default duration of timed events is one hour
+	_, err = tool.Call(ctx, `{"title":"Call","start":"2025-10-03T10:00:00Z"}`)
+	require.NoError(t, err)
+	require.Equal(t, time.Hour, store.events[2].End.Sub(store.events[2].Start))
+}
+
+func TestCalendarEventTool_Errors(t *testing.T) {
+	store := &eventStore{}
+	tool := tools.NewCalendarEventTool(store, "http://localhost:8080")
+	ctx := tools.WithConversation(context.Background(), primitive.NewObjectID().Hex())
+
+	_, err := tool.Call(context.Background(), `{"title":"Call","start":"2025-10-03"}`)
+	require.ErrorContains(t, err, "within a conversation")
+
+	_, err = tool.Call(ctx, `{"title":"Call","start":"tomorrow"}`)
+	require.ErrorContains(t, err, "invalid start")
+
+	_, err = tool.Call(ctx, `{"title":"Call","start":"2025-10-03T10:00:00Z","end":"2025-10-03T09:00:00Z"}`)
+	require.ErrorContains(t, err, "end must be after start")
+
+	_, err = tool.Call(ctx, `{"title":"Call","start":"2025-10-03","end":"2025-10-03T09:00:00Z"}`)
+	require.ErrorContains(t, err, "both be dates")
+
+	_, err = tool.Call(ctx, `{"title":"Call","start":"2025-10-03T10:00","timezone":"Mars/Olympus"}`)
+	require.ErrorContains(t, err, "unknown timezone")
+
+	require.Empty(t, store.events)
+}
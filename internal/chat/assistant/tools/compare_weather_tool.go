@@ -0,0 +1,184 @@
+package tools
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/model"
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/weather"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	maxCompareLocations = 6
+	maxCompareDays      = 14
+	// maxCompareFetches is the number of places whose weather is fetched at once.
+	maxCompareFetches = 3
+)
+
+type compareWeatherArgs struct {
+	Locations []string `json:"locations" required:"true" description:"Places to compare (2-6), e.g. [\"Porto\", \"Seville\", \"Valencia\"]."`
+	From      string   `json:"from" required:"true" description:"First day YYYY-MM-DD."`
+	To        string   `json:"to,omitempty" description:"Optional last day YYYY-MM-DD, at most 14 days after from. Defaults to from."`
+}
+
+// NewCompareWeatherTool compares the weather of several places over the same days, fetched concurrently
+// with a single date range lookup per place. Places are resolved like get_weather does, with places.
+func NewCompareWeatherTool(provider weather.Provider, places *Places) Tool {
+	return Typed("compare_weather",
+		"Compares the weather of several places over a date range, e.g. to pick a trip destination. Returns one row per place with the average high and low, total rain, rainy days and max wind over the range.",
+		func(ctx context.Context, args compareWeatherArgs) (string, error) {
+			return compareWeather(ctx, provider, places, args)
+		})
+}
+
+// weatherSummary is the row of a place in the comparison.
+type weatherSummary struct {
+	place      string
+	kinds      []weather.DataKind
+	days       []weather.DailyForecast
+	candidates []weather.Geocode
+	err        error
+}
+
+func compareWeather(ctx context.Context, provider weather.Provider, places *Places, args compareWeatherArgs) (string, error) {
+	if len(args.Locations) < 2 || len(args.Locations) > maxCompareLocations {
+		return "", fmt.Errorf("compare between 2 and %d locations", maxCompareLocations)
+	}
+	from, err := time.Parse(time.DateOnly, args.From)
+	if err != nil {
+		return "", fmt.Errorf("invalid from %q, use YYYY-MM-DD", args.From)
+	}
+	to := from
+	if args.To != "" {
+		if to, err = time.Parse(time.DateOnly, args.To); err != nil {
+			return "", fmt.Errorf("invalid to %q, use YYYY-MM-DD", args.To)
+		}
+	}
+	if to.Before(from) {
+		return "", errors.New("to must not be before from")
+	}
+	if days := int(to.Sub(from).Hours()/24) + 1; days > maxCompareDays {
+		return "", fmt.Errorf("compare at most %d days at once", maxCompareDays)
+	}
+
+	rows := make([]weatherSummary, len(args.Locations))
+	var g errgroup.Group
+	g.SetLimit(maxCompareFetches)
+	for i, location := range args.Locations {
+		g.Go(func() error {
+			rows[i] = summarizeWeather(ctx, provider, places, location, from, to)
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	// like holiday comparisons, places that failed are reported unless all did
+	var errs []error
+	for _, r := range rows {
+		if r.err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", r.place, r.err))
+		}
+	}
+	if len(errs) == len(rows) {
+		return "", errors.Join(errs...)
+	}
+
+	prefs := PreferencesFrom(ctx)
+	var b strings.Builder
+	fmt.Fprintf(&b, "Weather from %s to %s:\n", prefs.Date(from), prefs.Date(to))
+	b.WriteString(tableRow(compareColumns...))
+	b.WriteString("|" + strings.Repeat("---|", len(compareColumns)) + "\n")
+	for _, r := range rows {
+		switch {
+		case len(r.candidates) > 0:
+			labels := make([]string, len(r.candidates))
+			for i, c := range r.candidates {
+				labels[i] = c.Label()
+			}
+			b.WriteString(tableRow(r.place, "ambiguous, ask the user: "+strings.Join(labels, "; ")))
+		case r.err != nil:
+			b.WriteString(tableRow(r.place, fmt.Sprintf("unavailable (%v)", r.err)))
+		default:
+			b.WriteString(r.row(prefs))
+		}
+	}
+	return b.String(), nil
+}
+
+func summarizeWeather(ctx context.Context, provider weather.Provider, places *Places, location string, from, to time.Time) weatherSummary {
+	s := weatherSummary{place: location}
+	resolved := resolvePlace(ctx, provider, places, location)
+	if resolved.Place == nil && len(resolved.Candidates) > 0 {
+		s.candidates = resolved.Candidates
+		return s
+	}
+	if resolved.Place != nil {
+		s.place = resolved.Place.Label()
+	}
+
+	res, err := provider.Fetch(ctx, weather.Query{
+		Location: location,
+		Place:    resolved.Place,
+		Date:     from,
+		EndDate:  to,
+	})
+	if err != nil {
+		s.err = err
+		return s
+	}
+	for _, d := range res.Forecast {
+		if d.Date.Before(from) || d.Date.After(to) {
+			continue
+		}
+		s.days = append(s.days, d)
+		if kind := cmp.Or(d.Kind, res.Kind); !slices.Contains(s.kinds, kind) {
+			s.kinds = append(s.kinds, kind)
+		}
+	}
+	return s
+}
+
+// rainyDayMM is the precipitation from which a day counts as rainy.
+const rainyDayMM = 1.0
+
+func (s weatherSummary) row(prefs *model.Preferences) string {
+	if len(s.days) == 0 {
+		return tableRow(s.place, "no data")
+	}
+
+	var high, low, rain, wind float64
+	rainy := 0
+	for _, d := range s.days {
+		high += d.MaxTempC
+		low += d.MinTempC
+		rain += d.PrecipMM
+		wind = max(wind, d.WindMaxKmh)
+		if d.PrecipMM >= rainyDayMM {
+			rainy++
+		}
+	}
+	n := float64(len(s.days))
+
+	kinds := make([]string, len(s.kinds))
+	for i, k := range s.kinds {
+		kinds[i] = string(k)
+	}
+	return tableRow(s.place, prefs.Temperature(high/n), prefs.Temperature(low/n), prefs.Precipitation(rain),
+		fmt.Sprintf("%d/%d", rainy, len(s.days)), prefs.Speed(wind), strings.Join(kinds, ", "))
+}
+
+var compareColumns = []string{"Place", "Avg high", "Avg low", "Rain", "Rainy days", "Max wind", "Data"}
+
+// tableRow is a markdown row of the comparison, padded with empty cells up to the number of columns.
+func tableRow(cells ...string) string {
+	for len(cells) < len(compareColumns) {
+		cells = append(cells, "")
+	}
+	return "| " + strings.Join(cells, " | ") + " |\n"
+}
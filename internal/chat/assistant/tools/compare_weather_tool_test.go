@@ -0,0 +1,124 @@
+package tools_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/assistant/tools"
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/weather"
+	"github.com/stretchr/testify/require"
+)
+
+// compareServer is an Open-Meteo server where every place has the same weather every day, the
+// temperatures growing with the latitude.
+func compareServer(t *testing.T, requests *atomic.Int32) tools.Tool {
+	t.Helper()
+	places := map[string]map[string]any{
+		"Porto":    {"name": "Porto", "admin1": "Porto", "country": "Portugal", "latitude": 41.1, "longitude": -8.6},
+		"Seville":  {"name": "Seville", "admin1": "Andalusia", "country": "Spain", "latitude": 37.4, "longitude": -6.0},
+		"Valencia": {"name": "Valencia", "admin1": "Valencia", "country": "Spain", "latitude": 39.5, "longitude": -0.4},
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path == "/v1/search" {
+			results := []any{}
+			if p, ok := places[q.Get("name")]; ok {
+				results = append(results, p)
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"results": results})
+			return
+		}
+		requests.Add(1)
+
+		from, to := time.Now().UTC(), time.Now().UTC()
+		if q.Has("start_date") {
+			from, _ = time.Parse(time.DateOnly, q.Get("start_date"))
+			to, _ = time.Parse(time.DateOnly, q.Get("end_date"))
+		} else if q.Has("forecast_days") {
+			var days int
+			_ = json.Unmarshal([]byte(q.Get("forecast_days")), &days)
+			to = from.AddDate(0, 0, days-1)
+		}
+		lat := q.Get("latitude")
+		high := map[string]float64{"41.1000": 19, "37.4000": 27, "39.5000": 24}[lat]
+		rain := map[string]float64{"41.1000": 6.5, "37.4000": 0, "39.5000": 0.4}[lat]
+
+		daily := map[string][]any{}
+		for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+			daily["time"] = append(daily["time"], d.Format(time.DateOnly))
+			daily["weather_code"] = append(daily["weather_code"], 3)
+			daily["temperature_2m_max"] = append(daily["temperature_2m_max"], high)
+			daily["temperature_2m_min"] = append(daily["temperature_2m_min"], high-8)
+			daily["wind_speed_10m_max"] = append(daily["wind_speed_10m_max"], 20)
+			daily["precipitation_sum"] = append(daily["precipitation_sum"], rain)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"current": map[string]any{}, "daily": daily})
+	}))
+	t.Cleanup(srv.Close)
+	return tools.NewCompareWeatherTool(weather.NewOpenMeteo(weather.WithBaseURL(srv.URL), weather.WithHTTPClient(srv.Client())), nil)
+}
+
+func TestCompareWeatherTool_NextDays(t *testing.T) {
+	var requests atomic.Int32
+	ct := compareServer(t, &requests)
+	from := time.Now().UTC().AddDate(0, 0, 2).Format(time.DateOnly)
+	to := time.Now().UTC().AddDate(0, 0, 4).Format(time.DateOnly)
+
+	out, err := ct.Call(context.Background(), `{"locations":["Porto","Seville","Valencia","Atlantis"],"from":"`+from+`","to":"`+to+`"}`)
+	require.NoError(t, err)
+	require.Contains(t, out, "Weather from "+from+" to "+to+":")
+	require.Contains(t, out, "| Porto, Portugal | 19.0°C | 11.0°C | 19.5 mm | 3/3 | 20 km/h | forecast |")
+	require.Contains(t, out, "| Seville, Andalusia, Spain | 27.0°C | 19.0°C | 0.0 mm | 0/3 | 20 km/h | forecast |")
+	require.Contains(t, out, "| Valencia, Spain | 24.0°C | 16.0°C | 1.2 mm | 0/3 | 20 km/h | forecast |")
+	require.Contains(t, out, `| Atlantis | unavailable (openmeteo: no matching location found for "Atlantis") |`)
+	require.Equal(t, int32(3), requests.Load(), "one forecast call per place")
+
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n")[1:] {
+		require.Equal(t, 8, strings.Count(line, "|"), "every row has the 7 columns: %s", line)
+	}
+}
+
+func TestCompareWeatherTool_PastDays(t *testing.T) {
+	var requests atomic.Int32
+	ct := compareServer(t, &requests)
+	from := time.Now().UTC().AddDate(0, -2, 0)
+
+	out, err := ct.Call(context.Background(), `{"locations":["Porto","Seville"],"from":"`+from.Format(time.DateOnly)+`","to":"`+from.AddDate(0, 0, 6).Format(time.DateOnly)+`"}`)
+	require.NoError(t, err)
+	require.Contains(t, out, "| Porto, Portugal | 19.0°C | 11.0°C | 45.5 mm | 7/7 | 20 km/h | observed |")
+	require.Equal(t, int32(2), requests.Load(), "one date range lookup per place")
+}
+
+func TestCompareWeatherTool_Climatology(t *testing.T) {
+	var requests atomic.Int32
+	ct := compareServer(t, &requests)
+	from := time.Now().UTC().AddDate(0, 3, 0)
+
+	out, err := ct.Call(context.Background(), `{"locations":["Porto","Seville","Valencia"],"from":"`+from.Format(time.DateOnly)+`","to":"`+from.AddDate(0, 0, 13).Format(time.DateOnly)+`"}`)
+	require.NoError(t, err)
+	require.Contains(t, out, "| Porto, Portugal | 19.0°C | 11.0°C | 91.0 mm | 14/14 | 20 km/h | climatological |")
+	require.Equal(t, int32(3), requests.Load(), "one archive lookup per place")
+}
+
+func TestCompareWeatherTool_InvalidArgs(t *testing.T) {
+	var requests atomic.Int32
+	ct := compareServer(t, &requests)
+
+	for args, msg := range map[string]string{
+		`{"locations":["Porto"],"from":"2025-06-01"}`:                                "between 2 and 6",
+		`{"locations":["Porto","Seville"],"from":"June 1st"}`:                        "invalid from",
+		`{"locations":["Porto","Seville"],"from":"2025-06-10","to":"2025-06-01"}`:    "must not be before",
+		`{"locations":["Porto","Seville"],"from":"2025-06-01","to":"2025-07-01"}`:    "at most 14 days",
+		`{"locations":["Atlantis","Lemuria"],"from":"2025-06-01","to":"2025-06-02"}`: "no matching location",
+	} {
+		_, err := ct.Call(context.Background(), args)
+		require.ErrorContains(t, err, msg, args)
+	}
+	require.Zero(t, requests.Load())
+}
@@ -2,83 +2,140 @@ package tools
 
 import (
 	"context"
-	"encoding/json"
-	"os"
+	"errors"
+	"fmt"
 	"strings"
+	"sync"
 	"time"
 
-	ics "github.com/arran4/golang-ical"
 	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/assistant/calendar"
-
-	"github.com/openai/openai-go/v2"
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/model"
 )
 
-type HolidaysTool struct{}
+const maxHolidayRegions = 5
 
-func (HolidaysTool) Name() string { return "get_holidays" }
-func (HolidaysTool) Description() string {
-	return "Gets local bank and public holidays. Each line is 'YYYY-MM-DD: Holiday Name'."
+type holidaysArgs struct {
+	Country    string    `json:"country,omitempty" description:"Optional ISO 3166-1 alpha-2 country code, e.g. DE. Defaults to Catalonia, Spain."`
+	Region     string    `json:"region,omitempty" description:"Optional ISO 3166-2 subdivision code, e.g. ES-CT or CT together with country ES."`
+	Regions    []string  `json:"regions,omitempty" description:"Optional list of country or subdivision codes to compare, e.g. [\"ES-CT\", \"DE\"]. Overrides country and region."`
+	BeforeDate time.Time `json:"before_date,omitzero" description:"Optional RFC3339 date, return holidays before this date."`
+	AfterDate  time.Time `json:"after_date,omitzero" description:"Optional RFC3339 date, return holidays after this date."`
+	MaxCount   int       `json:"max_count,omitempty" description:"Optional limit of holidays to return per region." minimum:"1"`
 }
-func (HolidaysTool) Parameters() openai.FunctionParameters {
-	return openai.FunctionParameters{
-		"type": "object",
-		"properties": map[string]any{
-			"before_date": map[string]string{
-				"type":        "string",
-				"description": "Optional RFC3339 date, return holidays before this date.",
-			},
-			"after_date": map[string]string{
-				"type":        "string",
-				"description": "Optional RFC3339 date, return holidays after this date.",
-			},
-			"max_count": map[string]string{
-				"type":        "integer",
-				"description": "Optional limit of holidays to return.",
-			},
-		},
-	}
+
+// NewHolidaysTool lists the holidays of regions, loading their calendars with calendars.
+func NewHolidaysTool(regions calendar.Regions, calendars *calendar.Loader) Tool {
+	return Typed("get_holidays",
+		"Gets bank and public holidays of a country or region, or of several regions to compare them. Each region starts with a 'Holidays in <region>:' line followed by lines 'DATE: Holiday Name', or 'DATE to DATE: Name' for holidays of several days. Dates are YYYY-MM-DD, or in the user's locale format when known.",
+		func(ctx context.Context, args holidaysArgs) (string, error) {
+			return getHolidays(ctx, regions, calendars, args)
+		}).
+		WithCacheTTL(6 * time.Hour)
 }
 
-func (HolidaysTool) Call(ctx context.Context, raw string) (string, error) {
-	link := "https://www.officeholidays.com/ics/spain/catalonia"
-	if v := os.Getenv("HOLIDAY_CALENDAR_LINK"); v != "" {
-		link = v
+func getHolidays(ctx context.Context, regions calendar.Regions, calendars *calendar.Loader, args holidaysArgs) (string, error) {
+	var selected []calendar.Region
+	var notes []string
+	if len(args.Regions) > 0 {
+		if len(args.Regions) > maxHolidayRegions {
+			return "", fmt.Errorf("at most %d regions can be compared at once", maxHolidayRegions)
+		}
+		for _, code := range args.Regions {
+			country, subdivision, ok := strings.Cut(code, "-")
+			if ok {
+				subdivision = code
+			}
+			region, fallback, err := regions.Resolve(country, subdivision)
+			if err != nil {
+				return "", err
+			}
+			selected = append(selected, region)
+			if fallback {
+				notes = append(notes, fallbackNote(code, region))
+			}
+		}
+	} else {
+		region, fallback, err := regions.Resolve(args.Country, args.Region)
+		if err != nil {
+			return "", err
+		}
+		selected = append(selected, region)
+		if fallback {
+			notes = append(notes, fallbackNote(args.Region, region))
+		}
 	}
 
-	events, err := calendar.LoadCalendar(ctx, link)
+	sections := make([]string, len(selected))
+	errs := make([]error, len(selected))
+	var wg sync.WaitGroup
+	for i, region := range selected {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sections[i], errs[i] = regionHolidays(ctx, calendars, region, args)
+		}()
+	}
+	wg.Wait()
 
-	if err != nil {
-		return "failed to load holiday events", nil // devolvemos texto de error “suave”
+	// comparisons report the regions that could not be loaded, unless none could
+	failed := 0
+	for i, err := range errs {
+		if err != nil {
+			failed++
+			sections[i] = fmt.Sprintf("Holidays in %s: unavailable (%v)", selected[i].Label(), err)
+		}
+	}
+	if failed == len(selected) {
+		return "", errors.Join(errs...)
 	}
 
-	var payload struct {
-		BeforeDate time.Time `json:"before_date,omitempty"`
-		AfterDate  time.Time `json:"after_date,omitempty"`
-		MaxCount   int       `json:"max_count,omitempty"`
+	return strings.Join(append(notes, sections...), "\n\n"), nil
+}
+
+func fallbackNote(code string, national calendar.Region) string {
+	return fmt.Sprintf("No calendar for %s, showing the national holidays of %s.", strings.ToUpper(code), national.Label())
+}
+
+func regionHolidays(ctx context.Context, calendars *calendar.Loader, region calendar.Region, args holidaysArgs) (string, error) {
+	to := args.BeforeDate
+	if to.IsZero() {
+		to = time.Now().AddDate(2, 0, 0)
 	}
-	if err := json.Unmarshal([]byte(raw), &payload); err != nil {
-		return "failed to parse tool call arguments: " + err.Error(), nil
+
+	events, err := region.Events(ctx, calendars, args.AfterDate, to)
+	if err != nil {
+		return "", fmt.Errorf("failed to load holiday events of %s: %w", region.Code, err)
 	}
 
-	var holidays []string
+	prefs := PreferencesFrom(ctx)
+	holidays := []string{"Holidays in " + region.Label() + ":"}
 	for _, event := range events {
-		date, err := event.GetAllDayStartAt()
-		if err != nil {
-			continue
-		}
-
-		if payload.MaxCount > 0 && len(holidays) >= payload.MaxCount {
+		if args.MaxCount > 0 && len(holidays) > args.MaxCount {
 			break
 		}
-		if !payload.BeforeDate.IsZero() && date.After(payload.BeforeDate) {
+		if !args.BeforeDate.IsZero() && !event.Start.Before(args.BeforeDate) {
 			continue
 		}
-		if !payload.AfterDate.IsZero() && date.Before(payload.AfterDate) {
+		if !args.AfterDate.IsZero() && !event.Start.After(args.AfterDate) {
 			continue
 		}
 
-		holidays = append(holidays, date.Format(time.DateOnly)+": "+event.GetProperty(ics.ComponentPropertySummary).Value)
+		days := event.Days()
+		when := formatDay(prefs, days[0])
+		if len(days) > 1 {
+			when += " to " + formatDay(prefs, days[len(days)-1])
+		}
+		holidays = append(holidays, when+": "+event.Summary)
 	}
 
 	return strings.Join(holidays, "\n"), nil
 }
+
+// formatDay formats a YYYY-MM-DD day with the preferences of the user.
+func formatDay(prefs *model.Preferences, day string) string {
+	t, err := time.Parse(time.DateOnly, day)
+	if err != nil {
+		return day
+	}
+	return prefs.Date(t)
+}
@@ -5,10 +5,13 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/assistant/calendar"
 	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/assistant/tools"
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/model"
 	"github.com/stretchr/testify/require"
 )
 
@@ -40,7 +43,7 @@ func TestHolidaysTool_Basic(t *testing.T) {
 	_ = os.Setenv("HOLIDAY_CALENDAR_LINK", srv.URL)
 	defer os.Setenv("HOLIDAY_CALENDAR_LINK", old)
 
-	var ht tools.HolidaysTool
+	ht := tools.NewHolidaysTool(calendar.DefaultRegions(), calendar.NewLoader(0))
 	out, err := ht.Call(context.Background(), `{"max_count":2}`)
 	require.NoError(t, err)
 
@@ -65,10 +68,135 @@ func TestHolidaysTool_AfterFilter(t *testing.T) {
 	_ = os.Setenv("HOLIDAY_CALENDAR_LINK", srv.URL)
 	defer os.Setenv("HOLIDAY_CALENDAR_LINK", old)
 
-	var ht tools.HolidaysTool
+	ht := tools.NewHolidaysTool(calendar.DefaultRegions(), calendar.NewLoader(0))
 	out, err := ht.Call(context.Background(), `{"after_date":"2025-01-01T00:00:00Z","max_count":5}`)
 	require.NoError(t, err)
 
 	require.NotContains(t, out, "New Year's Day")
 	require.Contains(t, out, "Epiphany")
 }
+
+func TestHolidaysTool_ReturnsErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	t.Setenv("HOLIDAY_CALENDAR_LINK", srv.URL)
+	ht := tools.NewHolidaysTool(calendar.DefaultRegions(), calendar.NewLoader(0))
+
+	_, err := ht.Call(context.Background(), `{"after_date":"tomorrow"}`)
+	require.ErrorContains(t, err, "invalid arguments")
+
+	// the default regions fall back to their bundled calendars
+	out, err := ht.Call(context.Background(), `{"after_date":"2026-01-01T00:00:00Z","max_count":3}`)
+	require.NoError(t, err)
+	require.Contains(t, out, "Epiphany")
+
+	ht = tools.NewHolidaysTool(calendar.Regions{"ES-CT": {Code: "ES-CT", Source: srv.URL}}, calendar.NewLoader(0))
+	_, err = ht.Call(context.Background(), `{}`)
+	require.ErrorContains(t, err, "failed to load holiday events")
+}
+
+const germanICS = `BEGIN:VCALENDAR
+VERSION:2.0
+PRODID:-//Test//EN
+BEGIN:VEVENT
+UID:1
+DTSTART;VALUE=DATE:20251003
+SUMMARY:Day of German Unity
+END:VEVENT
+END:VCALENDAR
+`
+
+func holidayRegions(t *testing.T) calendar.Regions {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/calendar")
+		_, _ = w.Write([]byte(sampleICS))
+	}))
+	t.Cleanup(srv.Close)
+
+	path := filepath.Join(t.TempDir(), "de.ics")
+	require.NoError(t, os.WriteFile(path, []byte(germanICS), 0o644))
+
+	return calendar.Regions{
+		"ES-CT": {Code: "ES-CT", Name: "Catalonia, Spain", Source: srv.URL},
+		"DE":    {Code: "DE", Name: "Germany", Source: "file://" + path},
+		"FR":    {Code: "FR", Name: "France", Source: filepath.Join(t.TempDir(), "missing.ics")},
+	}
+}
+
+func TestHolidaysTool_CountryAndRegion(t *testing.T) {
+	ht := tools.NewHolidaysTool(holidayRegions(t), calendar.NewLoader(0))
+
+	out, err := ht.Call(context.Background(), `{"country":"de"}`)
+	require.NoError(t, err)
+	require.Equal(t, "Holidays in Germany (DE):\n2025-10-03: Day of German Unity", out)
+
+	out, err = ht.Call(context.Background(), `{"country":"ES","region":"CT","max_count":1}`)
+	require.NoError(t, err)
+	require.Equal(t, "Holidays in Catalonia, Spain (ES-CT):\n2025-01-01: New Year's Day", out)
+
+	// unknown subdivisions fall back to the national calendar
+	out, err = ht.Call(context.Background(), `{"country":"DE","region":"DE-BY"}`)
+	require.NoError(t, err)
+	require.Contains(t, out, "No calendar for DE-BY, showing the national holidays of Germany (DE).")
+	require.Contains(t, out, "Day of German Unity")
+
+	_, err = ht.Call(context.Background(), `{"country":"JP"}`)
+	require.ErrorContains(t, err, `unknown region "JP"`)
+}
+
+func TestHolidaysTool_CompareRegions(t *testing.T) {
+	ht := tools.NewHolidaysTool(holidayRegions(t), calendar.NewLoader(0))
+
+	out, err := ht.Call(context.Background(), `{"regions":["ES-CT","DE"]}`)
+	require.NoError(t, err)
+	require.Equal(t, "Holidays in Catalonia, Spain (ES-CT):\n2025-01-01: New Year's Day\n2025-01-06: Epiphany\n\n"+
+		"Holidays in Germany (DE):\n2025-10-03: Day of German Unity", out)
+
+	// a region that cannot be loaded does not hide the others
+	out, err = ht.Call(context.Background(), `{"regions":["DE","FR"]}`)
+	require.NoError(t, err)
+	require.Contains(t, out, "Day of German Unity")
+	require.Contains(t, out, "Holidays in France (FR): unavailable")
+
+	_, err = ht.Call(context.Background(), `{"regions":["FR"]}`)
+	require.Error(t, err)
+}
+
+func TestHolidaysTool_RecurringAndMultiDayEvents(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "team.ics")
+	require.NoError(t, os.WriteFile(path, []byte(`BEGIN:VCALENDAR
+VERSION:2.0
+PRODID:-//Test//EN
+BEGIN:VEVENT
+UID:anniversary
+DTSTART;VALUE=DATE:20200315
+RRULE:FREQ=YEARLY
+SUMMARY:Company anniversary
+END:VEVENT
+BEGIN:VEVENT
+UID:closure
+DTSTART;VALUE=DATE:20250804
+DTEND;VALUE=DATE:20250816
+SUMMARY:Summer closure
+END:VEVENT
+END:VCALENDAR
+`), 0o644))
+
+	ht := tools.NewHolidaysTool(calendar.Regions{"ES-CT": {Code: "ES-CT", Name: "Team", Source: path}}, calendar.NewLoader(0))
+	out, err := ht.Call(context.Background(), `{"after_date":"2025-01-01T00:00:00Z","before_date":"2026-12-31T00:00:00Z"}`)
+	require.NoError(t, err)
+	require.Equal(t, "Holidays in Team (ES-CT):\n"+
+		"2025-03-15: Company anniversary\n"+
+		"2025-08-04 to 2025-08-15: Summer closure\n"+
+		"2026-03-15: Company anniversary", out)
+
+	ctx := tools.WithPreferences(context.Background(), &model.Preferences{Locale: "de-DE"})
+	out, err = ht.Call(ctx, `{"after_date":"2025-01-01T00:00:00Z","before_date":"2025-12-31T00:00:00Z"}`)
+	require.NoError(t, err)
+	require.Equal(t, "Holidays in Team (ES-CT):\n"+
+		"15.03.2025: Company anniversary\n"+
+		"04.08.2025 to 15.08.2025: Summer closure", out)
+}
@@ -0,0 +1,312 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	"github.com/openai/openai-go/v2"
+	"gopkg.in/yaml.v3"
+)
+
+const maxHTTPToolResponse = 16 * 1024
+
+// HTTPToolConfig declares a tool that calls an HTTP endpoint. {name} placeholders in URL, Query and
+// Body are replaced with the (escaped) arguments of the call, ${VAR} in Headers with environment
+// variables. The response is returned as is, or reduced with Response.JSONPath or Response.Template.
+type HTTPToolConfig struct {
+	Name        string            `json:"name" yaml:"name"`
+	Description string            `json:"description" yaml:"description"`
+	Parameters  map[string]any    `json:"parameters" yaml:"parameters"`
+	Method      string            `json:"method" yaml:"method"`
+	URL         string            `json:"url" yaml:"url"`
+	Query       map[string]string `json:"query" yaml:"query"`
+	Headers     map[string]string `json:"headers" yaml:"headers"`
+	// Body is sent for methods other than GET, by default the call arguments are sent as JSON.
+	Body     string           `json:"body" yaml:"body"`
+	Timeout  string           `json:"timeout" yaml:"timeout"`
+	Response HTTPToolResponse `json:"response" yaml:"response"`
+	// Confirm makes the assistant ask the user before calling the endpoint.
+	Confirm bool `json:"confirm" yaml:"confirm"`
+}
+
+// HTTPToolResponse reduces the response of an HTTP tool: JSONPath selects a value and Template, a
+// text/template, renders the selected value (or the whole response).
+type HTTPToolResponse struct {
+	JSONPath string `json:"jsonpath" yaml:"jsonpath"`
+	Template string `json:"template" yaml:"template"`
+}
+
+type HTTPTool struct {
+	cfg      HTTPToolConfig
+	tmpl     *template.Template
+	client   *http.Client
+	jsonPath []pathStep
+}
+
+var placeholder = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+func NewHTTPTool(cfg HTTPToolConfig) (*HTTPTool, error) {
+	if cfg.Name == "" || cfg.URL == "" {
+		return nil, errors.New("http tool: name and url are required")
+	}
+	if cfg.Method == "" {
+		cfg.Method = http.MethodGet
+	}
+	cfg.Method = strings.ToUpper(cfg.Method)
+
+	timeout := 10 * time.Second
+	if cfg.Timeout != "" {
+		d, err := time.ParseDuration(cfg.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("http tool %s: invalid timeout: %w", cfg.Name, err)
+		}
+		timeout = d
+	}
+
+	t := &HTTPTool{cfg: cfg, client: &http.Client{Timeout: timeout}}
+
+	if cfg.Response.JSONPath != "" {
+		steps, err := parseJSONPath(cfg.Response.JSONPath)
+		if err != nil {
+			return nil, fmt.Errorf("http tool %s: %w", cfg.Name, err)
+		}
+		t.jsonPath = steps
+	}
+	if cfg.Response.Template != "" {
+		tmpl, err := template.New(cfg.Name).Option("missingkey=zero").Parse(cfg.Response.Template)
+		if err != nil {
+			return nil, fmt.Errorf("http tool %s: invalid response template: %w", cfg.Name, err)
+		}
+		t.tmpl = tmpl
+	}
+
+	return t, nil
+}
+
+// LoadHTTPTools reads the tool declarations of a YAML (or JSON) file with a top level "tools" list.
+func LoadHTTPTools(path string) ([]Tool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file struct {
+		Tools []HTTPToolConfig `yaml:"tools"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	out := make([]Tool, 0, len(file.Tools))
+	for _, cfg := range file.Tools {
+		t, err := NewHTTPTool(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+func (t *HTTPTool) Name() string        { return t.cfg.Name }
+func (t *HTTPTool) Description() string { return t.cfg.Description }
+
+func (t *HTTPTool) RequiresConfirmation() bool { return t.cfg.Confirm }
+
+func (t *HTTPTool) Parameters() openai.FunctionParameters {
+	if len(t.cfg.Parameters) == 0 {
+		return openai.FunctionParameters{"type": "object", "properties": map[string]any{}}
+	}
+	return openai.FunctionParameters(t.cfg.Parameters)
+}
+
+func (t *HTTPTool) Call(ctx context.Context, rawArgs string) (string, error) {
+	args := map[string]any{}
+	if strings.TrimSpace(rawArgs) != "" {
+		if err := json.Unmarshal([]byte(rawArgs), &args); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+	}
+
+	req, err := t.request(ctx, args)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%s request failed: %w", t.cfg.Name, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("%s returned status %d: %s", t.cfg.Name, resp.StatusCode, truncate(strings.TrimSpace(string(body)), 512))
+	}
+
+	return t.render(body)
+}
+
+func (t *HTTPTool) request(ctx context.Context, args map[string]any) (*http.Request, error) {
+	base, query, _ := strings.Cut(t.cfg.URL, "?")
+
+	path, err := expand(base, args, url.PathEscape)
+	if err != nil {
+		return nil, err
+	}
+	u, err := url.Parse(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url: %w", err)
+	}
+
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url query: %w", err)
+	}
+	for k, vs := range values {
+		for i, v := range vs {
+			if vs[i], err = expand(v, args, nil); err != nil {
+				return nil, err
+			}
+		}
+		values[k] = vs
+	}
+	for k, tmpl := range t.cfg.Query {
+		// optional parameters are left out when the model did not provide them
+		if m := placeholder.FindStringSubmatch(tmpl); m != nil && m[0] == tmpl && args[m[1]] == nil {
+			continue
+		}
+		v, err := expand(tmpl, args, nil)
+		if err != nil {
+			return nil, err
+		}
+		values.Set(k, v)
+	}
+	u.RawQuery = values.Encode()
+
+	var body io.Reader
+	if t.cfg.Method != http.MethodGet && t.cfg.Method != http.MethodHead {
+		b, err := t.body(args)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, t.cfg.Method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	for k, v := range t.cfg.Headers {
+		req.Header.Set(k, os.ExpandEnv(v))
+	}
+	return req, nil
+}
+
+func (t *HTTPTool) body(args map[string]any) ([]byte, error) {
+	if t.cfg.Body == "" {
+		return json.Marshal(args)
+	}
+	s, err := expand(t.cfg.Body, args, func(s string) string {
+		b, _ := json.Marshal(s)
+		return string(b[1 : len(b)-1])
+	})
+	return []byte(s), err
+}
+
+func (t *HTTPTool) render(body []byte) (string, error) {
+	if t.jsonPath == nil && t.tmpl == nil {
+		return truncate(string(body), maxHTTPToolResponse), nil
+	}
+
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		return "", fmt.Errorf("%s returned invalid JSON: %w", t.cfg.Name, err)
+	}
+
+	if t.jsonPath != nil {
+		v = evalJSONPath(v, t.jsonPath)
+	}
+
+	if t.tmpl != nil {
+		var buf bytes.Buffer
+		if err := t.tmpl.Execute(&buf, v); err != nil {
+			return "", fmt.Errorf("%s response template: %w", t.cfg.Name, err)
+		}
+		return truncate(buf.String(), maxHTTPToolResponse), nil
+	}
+
+	if s, ok := v.(string); ok {
+		return truncate(s, maxHTTPToolResponse), nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return truncate(string(b), maxHTTPToolResponse), nil
+}
+
+// expand replaces {name} placeholders with the arguments, escaped with escape when not nil.
+func expand(s string, args map[string]any, escape func(string) string) (string, error) {
+	var missing []string
+	out := placeholder.ReplaceAllStringFunc(s, func(m string) string {
+		name := m[1 : len(m)-1]
+		v, ok := args[name]
+		if !ok || v == nil {
+			missing = append(missing, name)
+			return ""
+		}
+
+		var str string
+		switch v := v.(type) {
+		case string:
+			str = v
+		case float64:
+			str = strconv.FormatFloat(v, 'f', -1, 64)
+		case bool:
+			str = strconv.FormatBool(v)
+		default:
+			b, _ := json.Marshal(v)
+			str = string(b)
+		}
+		if escape != nil {
+			str = escape(str)
+		}
+		return str
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("missing required argument(s): %s", strings.Join(missing, ", "))
+	}
+	return out, nil
+}
+
+// truncate cuts s to at most n bytes without splitting a rune.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n] + "..."
+}
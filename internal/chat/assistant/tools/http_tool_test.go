@@ -0,0 +1,152 @@
+package tools_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/assistant/tools"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadHTTPTools_CallsEndpointAndExtractsJSONPath(t *testing.T) {
+	t.Setenv("INVENTORY_TOKEN", "secret")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/stores/New%20York/stock", r.URL.EscapedPath())
+		require.Equal(t, "a&b", r.URL.Query().Get("sku"))
+		require.Equal(t, "json", r.URL.Query().Get("format"))
+		require.False(t, r.URL.Query().Has("limit"), "optional parameters are omitted")
+		require.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+
+		_, _ = w.Write([]byte(`{"store":"NY","items":[{"name":"apples","qty":3},{"name":"pears","qty":0}]}`))
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "tools.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+tools:
+  - name: get_stock
+    description: Get the stock of a store
+    parameters:
+      type: object
+      properties:
+        store: {type: string}
+        sku: {type: string}
+        limit: {type: integer}
+      required: [store, sku]
+    url: `+srv.URL+`/stores/{store}/stock?format=json
+    query:
+      sku: "{sku}"
+      limit: "{limit}"
+    headers:
+      Authorization: Bearer ${INVENTORY_TOKEN}
+    response:
+      jsonpath: $.items[*].name
+`), 0o644))
+
+	ts, err := tools.LoadHTTPTools(path)
+	require.NoError(t, err)
+	require.Len(t, ts, 1)
+
+	tool := ts[0]
+	require.Equal(t, "get_stock", tool.Name())
+	require.Equal(t, "object", tool.Parameters()["type"])
+
+	out, err := tool.Call(context.Background(), `{"store":"New York","sku":"a&b"}`)
+	require.NoError(t, err)
+	require.JSONEq(t, `["apples","pears"]`, out)
+
+	_, err = tool.Call(context.Background(), `{"sku":"a"}`)
+	require.ErrorContains(t, err, "missing required argument(s): store")
+}
+
+func TestHTTPTool_PostsArgumentsAndRendersTemplate(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+
+		body, _ := io.ReadAll(r.Body)
+		var args map[string]any
+		require.NoError(t, json.Unmarshal(body, &args))
+		require.Equal(t, "Ana", args["name"])
+
+		_, _ = w.Write([]byte(`{"ticket":{"id":42,"status":"open"}}`))
+	}))
+	defer srv.Close()
+
+	tool, err := tools.NewHTTPTool(tools.HTTPToolConfig{
+		Name:     "open_ticket",
+		Method:   "post",
+		URL:      srv.URL + "/tickets",
+		Response: tools.HTTPToolResponse{JSONPath: "$.ticket", Template: "Ticket #{{.id}} is {{.status}}"},
+	})
+	require.NoError(t, err)
+
+	out, err := tool.Call(context.Background(), `{"name":"Ana"}`)
+	require.NoError(t, err)
+	require.Equal(t, "Ticket #42 is open", out)
+}
+
+func TestHTTPTool_ReturnsErrorOnFailedStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "not found", http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	tool, err := tools.NewHTTPTool(tools.HTTPToolConfig{Name: "lookup", URL: srv.URL})
+	require.NoError(t, err)
+
+	_, err = tool.Call(context.Background(), ``)
+	require.EqualError(t, err, "lookup returned status 404: not found")
+}
+
+func TestHTTPTool_FormatsNumbersWithoutExponent(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.URL.Path))
+	}))
+	defer srv.Close()
+
+	tool, err := tools.NewHTTPTool(tools.HTTPToolConfig{Name: "lookup", URL: srv.URL + "/{id}/{ratio}"})
+	require.NoError(t, err)
+
+	out, err := tool.Call(context.Background(), `{"id":1000000000000000000000,"ratio":0.5}`)
+	require.NoError(t, err)
+	require.Equal(t, "/1000000000000000000000/0.5", out)
+}
+
+func TestHTTPTool_TruncatesOnRuneBoundaries(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		_, _ = w.Write([]byte("a" + strings.Repeat("é", 300)))
+	}))
+	defer srv.Close()
+
+	tool, err := tools.NewHTTPTool(tools.HTTPToolConfig{Name: "lookup", URL: srv.URL})
+	require.NoError(t, err)
+
+	_, err = tool.Call(context.Background(), ``)
+	require.Error(t, err)
+	require.True(t, utf8.ValidString(err.Error()))
+	require.Equal(t, "lookup returned status 502: a"+strings.Repeat("é", 255)+"...", err.Error())
+}
+
+func TestHTTPTool_TruncatesExtractedStrings(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"text": strings.Repeat("a", 20*1024)})
+	}))
+	defer srv.Close()
+
+	tool, err := tools.NewHTTPTool(tools.HTTPToolConfig{Name: "lookup", URL: srv.URL, Response: tools.HTTPToolResponse{JSONPath: "$.text"}})
+	require.NoError(t, err)
+
+	out, err := tool.Call(context.Background(), ``)
+	require.NoError(t, err)
+	require.Equal(t, strings.Repeat("a", 16*1024)+"...", out)
+}
@@ -0,0 +1,118 @@
+package tools
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// pathStep is a step of the JSONPath subset supported by HTTP tools: $.a.b, $.a[0], $.a[*].b,
+// $['a b'] and $.a.*.
+type pathStep struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+func parseJSONPath(path string) ([]pathStep, error) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(path), "$")
+	if !ok {
+		return nil, fmt.Errorf("invalid jsonpath %q: must start with $", path)
+	}
+
+	steps := []pathStep{}
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, "."):
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			key := rest[:end]
+			if key == "" {
+				return nil, fmt.Errorf("invalid jsonpath %q: empty key", path)
+			}
+			steps = append(steps, pathStep{key: key, wildcard: key == "*"})
+			rest = rest[end:]
+
+		case strings.HasPrefix(rest, "["):
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("invalid jsonpath %q: missing ]", path)
+			}
+			inner := strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+
+			switch {
+			case inner == "*":
+				steps = append(steps, pathStep{wildcard: true})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				steps = append(steps, pathStep{key: inner[1 : len(inner)-1]})
+			default:
+				i, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("invalid jsonpath %q: bad index %q", path, inner)
+				}
+				steps = append(steps, pathStep{index: i, isIndex: true})
+			}
+
+		default:
+			return nil, fmt.Errorf("invalid jsonpath %q: unexpected %q", path, rest)
+		}
+	}
+	return steps, nil
+}
+
+// evalJSONPath returns the value at the path, or the list of values once a wildcard is involved.
+func evalJSONPath(v any, steps []pathStep) any {
+	values := []any{v}
+	multi := false
+
+	for _, s := range steps {
+		var next []any
+		for _, cur := range values {
+			switch {
+			case s.wildcard:
+				multi = true
+				switch cur := cur.(type) {
+				case []any:
+					next = append(next, cur...)
+				case map[string]any:
+					for _, e := range cur {
+						next = append(next, e)
+					}
+				}
+			case s.isIndex:
+				if arr, ok := cur.([]any); ok {
+					i := s.index
+					if i < 0 {
+						i += len(arr)
+					}
+					if i >= 0 && i < len(arr) {
+						next = append(next, arr[i])
+					}
+				}
+			default:
+				if obj, ok := cur.(map[string]any); ok {
+					if e, ok := obj[s.key]; ok {
+						next = append(next, e)
+					}
+				}
+			}
+		}
+		values = next
+	}
+
+	if multi {
+		if values == nil {
+			return []any{}
+		}
+		return values
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	return values[0]
+}
@@ -2,41 +2,19 @@ package tools
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"time"
-
-	"github.com/openai/openai-go/v2"
 )
 
-type TimeInTool struct{}
-
-func (TimeInTool) Name() string { return "time_in" }
-
-func (TimeInTool) Description() string {
-	return "Get the current date/time for a given IANA time zone (e.g. Europe/Madrid)."
+type timeInArgs struct {
+	Zone string `json:"zone" required:"true" description:"IANA time zone, e.g. Europe/Madrid, America/New_York"`
 }
 
-func (TimeInTool) Parameters() openai.FunctionParameters {
-	return openai.FunctionParameters{
-		"type": "object",
-		"properties": map[string]any{
-			"zone": map[string]any{
-				"type":        "string",
-				"description": "IANA time zone, e.g. Europe/Madrid, America/New_York",
-			},
-		},
-		"required": []string{"zone"},
-	}
+func NewTimeInTool() Tool {
+	return Typed("time_in", "Get the current date/time for a given IANA time zone (e.g. Europe/Madrid).", timeIn)
 }
 
-func (TimeInTool) Call(ctx context.Context, rawArgs string) (string, error) {
-	var args struct {
-		Zone string `json:"zone"`
-	}
-	if err := json.Unmarshal([]byte(rawArgs), &args); err != nil {
-		return "", fmt.Errorf("invalid arguments: %w", err)
-	}
+func timeIn(ctx context.Context, args timeInArgs) (string, error) {
 	if args.Zone == "" {
 		return "", fmt.Errorf("zone is required")
 	}
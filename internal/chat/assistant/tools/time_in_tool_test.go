@@ -9,7 +9,7 @@ import (
 
 func TestTimeInTool_ValidZone(t *testing.T) {
 	ctx := context.Background()
-	tool := TimeInTool{}
+	tool := NewTimeInTool()
 
 	out, err := tool.Call(ctx, `{"zone":"Europe/Madrid"}`)
 	require.NoError(t, err)
@@ -18,7 +18,7 @@ func TestTimeInTool_ValidZone(t *testing.T) {
 
 func TestTimeInTool_InvalidZone(t *testing.T) {
 	ctx := context.Background()
-	tool := TimeInTool{}
+	tool := NewTimeInTool()
 
 	_, err := tool.Call(ctx, `{"zone":"Mars/Phobos"}`)
 	require.Error(t, err)
@@ -2,23 +2,20 @@ package tools
 
 import (
 	"context"
+	"fmt"
 	"time"
-
-	"github.com/openai/openai-go/v2"
 )
 
-type TodayTool struct{}
-
-func (TodayTool) Name() string        { return "get_today_date" }
-func (TodayTool) Description() string { return "Get today's date and time in RFC3339 format" }
-
-func (TodayTool) Parameters() openai.FunctionParameters {
-	return openai.FunctionParameters{
-		"type":       "object",
-		"properties": map[string]any{},
-	}
-}
-
-func (TodayTool) Call(ctx context.Context, _ string) (string, error) {
-	return time.Now().Format(time.RFC3339), nil
+// NewTodayTool answers with the current time in RFC3339, in the time zone of the user and followed by
+// its local formatting when the user has preferences.
+func NewTodayTool() Tool {
+	return Typed("get_today_date", "Get today's date and time in RFC3339 format, in the user's time zone when known",
+		func(ctx context.Context, _ struct{}) (string, error) {
+			prefs := PreferencesFrom(ctx)
+			if prefs == nil {
+				return time.Now().Format(time.RFC3339), nil
+			}
+			now := time.Now().In(prefs.Location())
+			return fmt.Sprintf("%s (%s, %s, %s)", now.Format(time.RFC3339), now.Format("Monday"), prefs.DateTime(now), prefs.Location()), nil
+		})
 }
@@ -2,15 +2,17 @@ package tools_test
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/assistant/tools"
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/model"
 	"github.com/stretchr/testify/require"
 )
 
 func TestTodayTool_ReturnsRFC3339Now(t *testing.T) {
-	var tt tools.TodayTool
+	tt := tools.NewTodayTool()
 
 	out, err := tt.Call(context.Background(), `{}`)
 	require.NoError(t, err)
@@ -22,9 +24,25 @@ func TestTodayTool_ReturnsRFC3339Now(t *testing.T) {
 }
 
 func TestTodayTool_IgnoresArgs(t *testing.T) {
-	var tt tools.TodayTool
+	tt := tools.NewTodayTool()
 	out, err := tt.Call(context.Background(), `{"anything":"goes"}`)
 	require.NoError(t, err)
 	_, err = time.Parse(time.RFC3339, out)
 	require.NoError(t, err)
 }
+
+func TestTodayTool_UsesPreferences(t *testing.T) {
+	tt := tools.NewTodayTool()
+	ctx := tools.WithPreferences(context.Background(), &model.Preferences{Locale: "en-US", Timezone: "Asia/Tokyo"})
+
+	out, err := tt.Call(ctx, `{}`)
+	require.NoError(t, err)
+
+	stamp, local, ok := strings.Cut(out, " ")
+	require.True(t, ok)
+	parsed, err := time.Parse(time.RFC3339, stamp)
+	require.NoError(t, err)
+	require.True(t, strings.HasSuffix(stamp, "+09:00"), stamp)
+	require.Contains(t, local, parsed.Format("01/02/2006 3:04 PM"))
+	require.Contains(t, local, "Asia/Tokyo")
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"sort"
 
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/model"
 	"github.com/openai/openai-go/v2"
 )
 
@@ -14,34 +15,115 @@ type Tool interface {
 	Call(ctx context.Context, rawArgs string) (string, error)
 }
 
+// Confirmable is implemented by tools with side effects (bookings, emails...) that must only run once
+// the user has approved the call.
+type Confirmable interface {
+	RequiresConfirmation() bool
+}
+
+type conversationKey struct{}
+
+// WithConversation tells the tools which conversation they run for, see ConversationID.
+func WithConversation(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, conversationKey{}, id)
+}
+
+// ConversationID returns the conversation of a tool call, or "" outside of one.
+func ConversationID(ctx context.Context) string {
+	id, _ := ctx.Value(conversationKey{}).(string)
+	return id
+}
+
+type preferencesKey struct{}
+
+// WithPreferences tells the tools how the user wants units, dates and times, see PreferencesFrom.
+func WithPreferences(ctx context.Context, p *model.Preferences) context.Context {
+	return context.WithValue(ctx, preferencesKey{}, p)
+}
+
+// PreferencesFrom returns the preferences of the user, nil for the defaults. The methods of
+// model.Preferences accept nil.
+func PreferencesFrom(ctx context.Context) *model.Preferences {
+	p, _ := ctx.Value(preferencesKey{}).(*model.Preferences)
+	return p
+}
+
+type callObserverKey struct{}
+
+// WithCallObserver makes the registry report the name of every tool it dispatches a call to, e.g. to
+// record which tools answered a question.
+func WithCallObserver(ctx context.Context, observe func(name string)) context.Context {
+	return context.WithValue(ctx, callObserverKey{}, observe)
+}
+
+// Registry dispatches tool calls by name. Calls go through argument validation and, when enabled, the
+// result cache before reaching the tool.
 type Registry struct {
+	tools  map[string]Tool
 	byName map[string]Tool
+	cache  CacheStore
 }
 
 func NewRegistry(ts ...Tool) *Registry {
-	m := make(map[string]Tool, len(ts))
+	r := &Registry{}
 	for _, t := range ts {
-		m[t.Name()] = t
+		r.Register(t)
 	}
-	return &Registry{byName: m}
+	return r
 }
 
 func (r *Registry) Register(t Tool) {
 	if r.byName == nil {
+		r.tools = make(map[string]Tool)
 		r.byName = make(map[string]Tool)
 	}
-	r.byName[t.Name()] = t
+	r.tools[t.Name()] = t
+	r.byName[t.Name()] = r.wrap(t)
+}
+
+// EnableCache stores the results of every Cacheable tool, registered now or later, in store.
+func (r *Registry) EnableCache(store CacheStore) {
+	r.cache = store
+	for name, t := range r.tools {
+		r.byName[name] = r.wrap(t)
+	}
+}
+
+func (r *Registry) wrap(t Tool) Tool {
+	return observedTool{Validated(Cached(t, r.cache))}
+}
+
+// observedTool reports its calls to the observer of the context, see WithCallObserver.
+type observedTool struct {
+	Tool
+}
+
+func (o observedTool) Unwrap() Tool { return o.Tool }
+
+func (o observedTool) Call(ctx context.Context, rawArgs string) (string, error) {
+	if observe, ok := ctx.Value(callObserverKey{}).(func(string)); ok {
+		observe(o.Name())
+	}
+	return o.Tool.Call(ctx, rawArgs)
 }
 
+// Tools returns the registered tools as they are, without validation or caching.
 func (r *Registry) Tools() []Tool {
-	out := make([]Tool, 0, len(r.byName))
-	for _, t := range r.byName {
+	out := make([]Tool, 0, len(r.tools))
+	for _, t := range r.tools {
 		out = append(out, t)
 	}
 	sort.Slice(out, func(i, j int) bool { return out[i].Name() < out[j].Name() })
 	return out
 }
 
+// RequiresConfirmation reports whether calls to the named tool need the user approval.
+func (r *Registry) RequiresConfirmation(name string) bool {
+	c, ok := r.tools[name].(Confirmable)
+	return ok && c.RequiresConfirmation()
+}
+
+// Get returns the tool to dispatch calls to.
 func (r *Registry) Get(name string) (Tool, bool) {
 	t, ok := r.byName[name]
 	return t, ok
@@ -9,6 +9,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/assistant/calendar"
 	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/assistant/tools"
 	"github.com/matteo-nyapa/tech-challenge-acai/internal/weather"
 	"github.com/stretchr/testify/require"
@@ -16,7 +17,7 @@ import (
 
 func TestTodayTool_Call_ReturnsRFC3339Date(t *testing.T) {
 	t.Parallel()
-	tool := tools.TodayTool{}
+	tool := tools.NewTodayTool()
 
 	out, err := tool.Call(context.Background(), "")
 	require.NoError(t, err)
@@ -26,7 +27,7 @@ func TestTodayTool_Call_ReturnsRFC3339Date(t *testing.T) {
 
 func TestTimeInTool_Call_ReturnsLocationTime(t *testing.T) {
 	ctx := context.Background()
-	reg := tools.NewRegistry(tools.TimeInTool{})
+	reg := tools.NewRegistry(tools.NewTimeInTool())
 
 	tool, ok := reg.Get("time_in")
 	require.True(t, ok, "time_in tool should exist")
@@ -54,7 +55,7 @@ END:VCALENDAR`
 	os.Setenv("HOLIDAY_CALENDAR_LINK", srv.URL)
 	defer os.Unsetenv("HOLIDAY_CALENDAR_LINK")
 
-	tool := tools.HolidaysTool{}
+	tool := tools.NewHolidaysTool(calendar.DefaultRegions(), calendar.NewLoader(0))
 	out, err := tool.Call(context.Background(), "{}")
 	require.NoError(t, err)
 	require.Contains(t, out, "Epiphany")
@@ -96,13 +97,7 @@ func TestWeatherTool_Call_UsesMockWeatherAPI(t *testing.T) {
 	}))
 	defer srv.Close()
 
-	os.Setenv("WEATHER_API_KEY", "fake-key")
-	defer os.Unsetenv("WEATHER_API_KEY")
-
-	weather.SetBaseURL(srv.URL)
-	weather.SetHTTPClient(srv.Client())
-
-	tool := tools.WeatherTool{}
+	tool := tools.NewWeatherTool(weather.NewWeatherAPI("fake-key", weather.WithBaseURL(srv.URL), weather.WithHTTPClient(srv.Client())), nil)
 	args, _ := json.Marshal(map[string]any{"location": "Barcelona", "days": 1})
 	out, err := tool.Call(context.Background(), string(args))
 	require.NoError(t, err)
@@ -113,10 +108,10 @@ func TestWeatherTool_Call_UsesMockWeatherAPI(t *testing.T) {
 
 func TestRegistry_BasicFunctions(t *testing.T) {
 	reg := tools.NewRegistry(
-		tools.TimeInTool{},
-		tools.TodayTool{},
-		tools.WeatherTool{},
-		tools.HolidaysTool{},
+		tools.NewTimeInTool(),
+		tools.NewTodayTool(),
+		tools.NewWeatherTool(weather.NewOpenMeteo(), nil),
+		tools.NewHolidaysTool(calendar.DefaultRegions(), calendar.NewLoader(0)),
 	)
 
 	_, ok := reg.Get("time_in")
@@ -0,0 +1,178 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/openai/openai-go/v2"
+)
+
+// TypedTool is a tool whose arguments are decoded into Args. Its schema is derived from the fields of
+// Args, see Typed.
+type TypedTool[Args any] struct {
+	name        string
+	description string
+	schema      map[string]any
+	handler     func(ctx context.Context, args Args) (string, error)
+	ttl         time.Duration
+	confirm     bool
+}
+
+// Typed builds a tool from a handler taking its arguments as a struct. The JSON schema of the
+// arguments is derived from the exported fields of Args: the json tag gives the name, and the
+// description, minimum, maximum, enum (comma separated) and required:"true" tags the constraints.
+//
+//	type weatherArgs struct {
+//		Location string `json:"location" required:"true" description:"City name"`
+//		Days     int    `json:"days,omitempty" minimum:"0" maximum:"10"`
+//	}
+//
+// The registry validates the arguments against the schema (see Validated) before they are decoded and
+// passed to handler.
+func Typed[Args any](name, description string, handler func(ctx context.Context, args Args) (string, error)) *TypedTool[Args] {
+	return &TypedTool[Args]{
+		name:        name,
+		description: description,
+		schema:      schemaFor(reflect.TypeFor[Args]()),
+		handler:     handler,
+	}
+}
+
+// WithCacheTTL makes the results of the tool cacheable for ttl, see Cacheable.
+func (t *TypedTool[Args]) WithCacheTTL(ttl time.Duration) *TypedTool[Args] {
+	t.ttl = ttl
+	return t
+}
+
+// WithConfirmation makes the assistant ask the user before running the tool, see Confirmable.
+func (t *TypedTool[Args]) WithConfirmation() *TypedTool[Args] {
+	t.confirm = true
+	return t
+}
+
+func (t *TypedTool[Args]) Name() string               { return t.name }
+func (t *TypedTool[Args]) Description() string        { return t.description }
+func (t *TypedTool[Args]) CacheTTL() time.Duration    { return t.ttl }
+func (t *TypedTool[Args]) RequiresConfirmation() bool { return t.confirm }
+
+func (t *TypedTool[Args]) Parameters() openai.FunctionParameters {
+	return openai.FunctionParameters(t.schema)
+}
+
+func (t *TypedTool[Args]) Call(ctx context.Context, rawArgs string) (string, error) {
+	if strings.TrimSpace(rawArgs) == "" {
+		rawArgs = "{}"
+	}
+
+	var args Args
+	if err := json.Unmarshal([]byte(rawArgs), &args); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+	return t.handler(ctx, args)
+}
+
+var timeType = reflect.TypeFor[time.Time]()
+
+func schemaFor(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case t.Kind() == reflect.Struct:
+		props := map[string]any{}
+		required := []string{}
+		addFields(t, props, &required)
+
+		schema := map[string]any{"type": "object", "properties": props}
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+		return schema
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": schemaFor(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object"}
+	}
+	return map[string]any{}
+}
+
+func addFields(t reflect.Type, props map[string]any, required *[]string) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			addFields(f.Type, props, required)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		prop := schemaFor(f.Type)
+		if d := f.Tag.Get("description"); d != "" {
+			prop["description"] = d
+		}
+		if v, ok := f.Tag.Lookup("minimum"); ok {
+			prop["minimum"] = number(v)
+		}
+		if v, ok := f.Tag.Lookup("maximum"); ok {
+			prop["maximum"] = number(v)
+		}
+		if v, ok := f.Tag.Lookup("enum"); ok {
+			var enum []any
+			for _, e := range strings.Split(v, ",") {
+				e = strings.TrimSpace(e)
+				if prop["type"] == "string" {
+					enum = append(enum, e)
+				} else {
+					enum = append(enum, number(e))
+				}
+			}
+			prop["enum"] = enum
+		}
+		if f.Tag.Get("required") == "true" {
+			*required = append(*required, name)
+		}
+
+		props[name] = prop
+	}
+}
+
+// number parses a numeric struct tag, panicking on invalid values as they are programming errors.
+func number(s string) any {
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		panic(fmt.Sprintf("tools: invalid numeric tag value %q", s))
+	}
+	return f
+}
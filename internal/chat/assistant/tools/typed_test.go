@@ -0,0 +1,69 @@
+package tools_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/assistant/tools"
+	"github.com/stretchr/testify/require"
+)
+
+type Paging struct {
+	Page int `json:"page,omitempty" minimum:"1"`
+}
+
+type searchArgs struct {
+	Paging
+	Query  string    `json:"query" required:"true" description:"What to look for"`
+	Limit  int       `json:"limit,omitempty" minimum:"1" maximum:"50"`
+	Sort   string    `json:"sort,omitempty" enum:"asc, desc"`
+	Tags   []string  `json:"tags,omitempty"`
+	Since  time.Time `json:"since,omitzero"`
+	hidden string
+}
+
+func TestTyped_DerivesSchemaFromStructTags(t *testing.T) {
+	tool := tools.Typed("search", "Searches", func(ctx context.Context, args searchArgs) (string, error) {
+		return "", nil
+	})
+
+	b, err := json.Marshal(tool.Parameters())
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"type": "object",
+		"properties": {
+			"page":  {"type": "integer", "minimum": 1},
+			"query": {"type": "string", "description": "What to look for"},
+			"limit": {"type": "integer", "minimum": 1, "maximum": 50},
+			"sort":  {"type": "string", "enum": ["asc", "desc"]},
+			"tags":  {"type": "array", "items": {"type": "string"}},
+			"since": {"type": "string", "format": "date-time"}
+		},
+		"required": ["query"]
+	}`, string(b))
+}
+
+func TestTyped_DecodesValidatedArguments(t *testing.T) {
+	tool := tools.Typed("search", "Searches", func(ctx context.Context, args searchArgs) (string, error) {
+		return fmt.Sprintf("%s limit=%d sort=%s page=%d", args.Query, args.Limit, args.Sort, args.Page), nil
+	}).WithCacheTTL(time.Minute)
+
+	require.Equal(t, time.Minute, tool.CacheTTL())
+
+	out, err := tool.Call(context.Background(), `{"query":"beaches","limit":5,"sort":"desc","page":2}`)
+	require.NoError(t, err)
+	require.Equal(t, "beaches limit=5 sort=desc page=2", out)
+
+	_, err = tools.Validated(tool).Call(context.Background(), `{"limit":100,"sort":"random"}`)
+	var verr *tools.ValidationError
+	require.True(t, errors.As(err, &verr))
+	require.Equal(t, []tools.Violation{
+		{Path: "query", Message: "is required"},
+		{Path: "limit", Message: "must be <= 50"},
+		{Path: "sort", Message: `must be one of ["asc", "desc"]`},
+	}, verr.Violations)
+}
@@ -0,0 +1,284 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Violation is an argument that does not match the schema of a tool. Path is empty for the
+// arguments object itself.
+type Violation struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// ValidationError is returned instead of calling a tool with invalid arguments. Its message is JSON
+// so that the model can read which arguments to correct.
+type ValidationError struct {
+	Tool       string      `json:"tool"`
+	Violations []Violation `json:"violations"`
+}
+
+func (e *ValidationError) Error() string {
+	b, _ := json.Marshal(struct {
+		Error string `json:"error"`
+		*ValidationError
+	}{Error: "invalid_arguments", ValidationError: e})
+	return string(b)
+}
+
+// Validate checks rawArgs against a JSON schema: types, required properties, additionalProperties,
+// enum, minimum/maximum (and their exclusive variants), minLength/maxLength, minItems/maxItems and
+// array items.
+func Validate(schema map[string]any, rawArgs string) []Violation {
+	return validateArgs(normalizeSchema(schema), rawArgs)
+}
+
+// validateArgs is Validate for a schema already normalized with normalizeSchema.
+func validateArgs(schema map[string]any, rawArgs string) []Violation {
+	raw := strings.TrimSpace(rawArgs)
+	if raw == "" {
+		raw = "{}"
+	}
+
+	var v any
+	if err := json.Unmarshal([]byte(raw), &v); err != nil {
+		return []Violation{{Message: "arguments are not valid JSON: " + err.Error()}}
+	}
+
+	var out []Violation
+	validate(schema, v, "", &out)
+	return out
+}
+
+// normalizeSchema turns schemas written with typed Go values ([]string, map[string]string, int...)
+// into their plain JSON representation.
+func normalizeSchema(schema map[string]any) map[string]any {
+	b, err := json.Marshal(schema)
+	if err != nil {
+		return schema
+	}
+	var out map[string]any
+	if err := json.Unmarshal(b, &out); err != nil {
+		return schema
+	}
+	return out
+}
+
+func validate(schema map[string]any, v any, path string, out *[]Violation) {
+	add := func(format string, args ...any) {
+		*out = append(*out, Violation{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if types := schemaTypes(schema["type"]); len(types) > 0 && !slices.ContainsFunc(types, func(t string) bool { return hasType(v, t) }) {
+		add("must be of type %s, got %s", strings.Join(types, " or "), typeOf(v))
+		return
+	}
+
+	if enum, ok := schema["enum"].([]any); ok && !slices.ContainsFunc(enum, func(e any) bool { return jsonEqual(e, v) }) {
+		add("must be one of %s", formatEnum(enum))
+	}
+
+	switch v := v.(type) {
+	case float64:
+		if m, ok := schema["minimum"].(float64); ok && v < m {
+			add("must be >= %v", m)
+		}
+		if m, ok := schema["maximum"].(float64); ok && v > m {
+			add("must be <= %v", m)
+		}
+		if m, ok := schema["exclusiveMinimum"].(float64); ok && v <= m {
+			add("must be > %v", m)
+		}
+		if m, ok := schema["exclusiveMaximum"].(float64); ok && v >= m {
+			add("must be < %v", m)
+		}
+
+	case string:
+		n := len([]rune(v))
+		if m, ok := schema["minLength"].(float64); ok && float64(n) < m {
+			add("must be at least %v characters long", m)
+		}
+		if m, ok := schema["maxLength"].(float64); ok && float64(n) > m {
+			add("must be at most %v characters long", m)
+		}
+
+	case []any:
+		if m, ok := schema["minItems"].(float64); ok && float64(len(v)) < m {
+			add("must have at least %v items", m)
+		}
+		if m, ok := schema["maxItems"].(float64); ok && float64(len(v)) > m {
+			add("must have at most %v items", m)
+		}
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, e := range v {
+				validate(items, e, fmt.Sprintf("%s[%d]", path, i), out)
+			}
+		}
+
+	case map[string]any:
+		props, _ := schema["properties"].(map[string]any)
+
+		if required, ok := schema["required"].([]any); ok {
+			for _, r := range required {
+				name, _ := r.(string)
+				if e, ok := v[name]; !ok || e == nil {
+					*out = append(*out, Violation{Path: join(path, name), Message: "is required"})
+				}
+			}
+		}
+
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			if p, ok := props[k].(map[string]any); ok {
+				if v[k] == nil && !slices.Contains(schemaTypes(p["type"]), "null") {
+					continue // null is treated as an omitted optional argument
+				}
+				validate(p, v[k], join(path, k), out)
+				continue
+			}
+			if extra, ok := schema["additionalProperties"].(bool); ok && !extra {
+				*out = append(*out, Violation{Path: join(path, k), Message: "is not a known argument"})
+			}
+		}
+	}
+}
+
+func schemaTypes(t any) []string {
+	switch t := t.(type) {
+	case string:
+		return []string{t}
+	case []any:
+		var out []string
+		for _, e := range t {
+			if s, ok := e.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+func hasType(v any, t string) bool {
+	switch t {
+	case "object":
+		_, ok := v.(map[string]any)
+		return ok
+	case "array":
+		_, ok := v.([]any)
+		return ok
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "number":
+		_, ok := v.(float64)
+		return ok
+	case "integer":
+		f, ok := v.(float64)
+		return ok && f == math.Trunc(f)
+	case "null":
+		return v == nil
+	}
+	return true
+}
+
+func typeOf(v any) string {
+	switch v := v.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case nil:
+		return "null"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func jsonEqual(a, b any) bool {
+	x, _ := json.Marshal(a)
+	y, _ := json.Marshal(b)
+	return string(x) == string(y)
+}
+
+func formatEnum(enum []any) string {
+	parts := make([]string, len(enum))
+	for i, e := range enum {
+		b, _ := json.Marshal(e)
+		parts[i] = string(b)
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+type validationMetrics struct {
+	Failures metric.Int64Counter
+}
+
+var sharedValidationMetrics = sync.OnceValue(newValidationMetrics)
+
+func newValidationMetrics() *validationMetrics {
+	failures, err := otel.Meter("acai/tools").Int64Counter("tools.validation.failures",
+		metric.WithDescription("Number of tool calls rejected because their arguments do not match the tool schema"),
+	)
+	if err != nil {
+		slog.Error("Failed to create tools.validation.failures counter", "error", err)
+	}
+	return &validationMetrics{Failures: failures}
+}
+
+type validatedTool struct {
+	Tool
+	schema  map[string]any
+	metrics *validationMetrics
+}
+
+// Validated wraps t so that calls whose arguments do not match t.Parameters() fail with a
+// *ValidationError without reaching the tool. The schema is normalized once, here.
+func Validated(t Tool) Tool {
+	return &validatedTool{Tool: t, schema: normalizeSchema(t.Parameters()), metrics: sharedValidationMetrics()}
+}
+
+func (v *validatedTool) Unwrap() Tool { return v.Tool }
+
+func (v *validatedTool) Call(ctx context.Context, rawArgs string) (string, error) {
+	if violations := validateArgs(v.schema, rawArgs); len(violations) > 0 {
+		v.metrics.Failures.Add(ctx, 1, metric.WithAttributes(attribute.String("tool.name", v.Name())))
+		return "", &ValidationError{Tool: v.Name(), Violations: violations}
+	}
+	return v.Tool.Call(ctx, rawArgs)
+}
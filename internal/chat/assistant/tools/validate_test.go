@@ -0,0 +1,72 @@
+package tools_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/assistant/calendar"
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/assistant/tools"
+	"github.com/stretchr/testify/require"
+)
+
+var forecastSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"location": map[string]string{"type": "string"},
+		"days":     map[string]any{"type": "integer", "minimum": 0, "maximum": 10},
+		"units":    map[string]any{"type": "string", "enum": []string{"metric", "imperial"}},
+		"hours":    map[string]any{"type": "array", "items": map[string]any{"type": "integer", "minimum": 0, "maximum": 23}},
+	},
+	"required":             []string{"location"},
+	"additionalProperties": false,
+}
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		name string
+		args string
+		want []tools.Violation
+	}{
+		{name: "valid", args: `{"location":"Porto","days":3,"units":"metric","hours":[0,12]}`},
+		{name: "null optional", args: `{"location":"Porto","days":null}`},
+		{name: "missing required", args: `{}`, want: []tools.Violation{{Path: "location", Message: "is required"}}},
+		{name: "empty args", args: ``, want: []tools.Violation{{Path: "location", Message: "is required"}}},
+		{name: "wrong type", args: `{"location":42}`, want: []tools.Violation{{Path: "location", Message: "must be of type string, got integer"}}},
+		{name: "not an integer", args: `{"location":"Porto","days":1.5}`, want: []tools.Violation{{Path: "days", Message: "must be of type integer, got number"}}},
+		{name: "above maximum", args: `{"location":"Porto","days":11}`, want: []tools.Violation{{Path: "days", Message: "must be <= 10"}}},
+		{name: "enum", args: `{"location":"Porto","units":"kelvin"}`, want: []tools.Violation{{Path: "units", Message: `must be one of ["metric", "imperial"]`}}},
+		{name: "items", args: `{"location":"Porto","hours":[1,24]}`, want: []tools.Violation{{Path: "hours[1]", Message: "must be <= 23"}}},
+		{name: "unknown argument", args: `{"location":"Porto","city":"Porto"}`, want: []tools.Violation{{Path: "city", Message: "is not a known argument"}}},
+		{name: "invalid json", args: `{"location":`, want: []tools.Violation{{Message: "arguments are not valid JSON: unexpected end of JSON input"}}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, tools.Validate(forecastSchema, tc.args))
+		})
+	}
+}
+
+func TestRegistry_RejectsInvalidArgumentsBeforeCall(t *testing.T) {
+	tool := &countingTool{}
+	reg := tools.NewRegistry(tools.NewHolidaysTool(calendar.DefaultRegions(), calendar.NewLoader(0)), tool)
+
+	_, err := reg.Execute(context.Background(), "get_holidays", `{"max_count":0,"before_date":12}`)
+
+	var verr *tools.ValidationError
+	require.True(t, errors.As(err, &verr))
+	require.Equal(t, "get_holidays", verr.Tool)
+	require.JSONEq(t, `{
+		"error": "invalid_arguments",
+		"tool": "get_holidays",
+		"violations": [
+			{"path": "before_date", "message": "must be of type string, got integer"},
+			{"path": "max_count", "message": "must be >= 1"}
+		]
+	}`, err.Error())
+
+	_, err = reg.Execute(context.Background(), "counting", `{"anything":true}`)
+	require.NoError(t, err)
+	require.Equal(t, 1, tool.calls)
+}
@@ -0,0 +1,67 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/weather"
+)
+
+const placeMemoryTTL = 24 * time.Hour
+
+// Places remembers the places resolved in each conversation, so that "Santiago" keeps meaning the one
+// the user picked. get_weather and compare_weather share it, a place picked with one holds for both. A
+// nil Places remembers nothing.
+type Places struct {
+	store CacheStore
+}
+
+// NewPlaces keeps the places in store, e.g. a MongoCache so they survive restarts.
+func NewPlaces(store CacheStore) *Places {
+	return &Places{store: store}
+}
+
+func (m *Places) get(ctx context.Context, conversationID, location string) (weather.Geocode, bool) {
+	var place weather.Geocode
+	if m == nil || conversationID == "" {
+		return place, false
+	}
+	v, ok, err := m.store.Get(ctx, placeKey(conversationID, location))
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to recall place", "location", location, "error", err)
+		return place, false
+	}
+	if !ok || json.Unmarshal([]byte(v), &place) != nil {
+		return place, false
+	}
+	return place, true
+}
+
+// remember stores the place under the location and under its bare name, "Santiago, Chile" is then
+// what "Santiago" means in the conversation.
+func (m *Places) remember(ctx context.Context, conversationID, location string, place weather.Geocode) {
+	if m == nil || conversationID == "" {
+		return
+	}
+	b, err := json.Marshal(place)
+	if err != nil {
+		return
+	}
+	name, _, _ := strings.Cut(location, ",")
+	for _, l := range []string{location, name} {
+		if err := m.store.Set(ctx, placeKey(conversationID, l), string(b), placeMemoryTTL); err != nil {
+			slog.WarnContext(ctx, "Failed to remember place", "location", l, "error", err)
+		}
+	}
+}
+
+func placeKey(conversationID, location string) string {
+	parts := strings.Split(strings.ToLower(location), ",")
+	for i, p := range parts {
+		parts[i] = strings.Join(strings.Fields(p), " ")
+	}
+	return "place:" + conversationID + ":" + strings.Join(parts, ",")
+}
@@ -2,67 +2,212 @@ package tools
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/model"
 	"github.com/matteo-nyapa/tech-challenge-acai/internal/weather"
-	"github.com/openai/openai-go/v2"
 )
 
-type WeatherTool struct{}
+type weatherArgs struct {
+	Location      string `json:"location" required:"true" description:"City or place, e.g. Barcelona"`
+	Days          int    `json:"days,omitempty" description:"Optional: number of forecast days (1-10)" minimum:"0" maximum:"10"`
+	Hours         int    `json:"hours,omitempty" description:"Optional: number of hours of hourly forecast from now (1-48), e.g. for 'will it rain this afternoon?'" minimum:"0" maximum:"48"`
+	IncludeAlerts bool   `json:"include_alerts,omitempty" description:"Optional: include the weather alerts in effect (storms, heat...)"`
+	IncludeAQI    bool   `json:"include_aqi,omitempty" description:"Optional: include the air quality"`
+	Date          string `json:"date,omitempty" description:"Optional: a single day YYYY-MM-DD in the past (observed weather) or the future (forecast, or the typical weather when too far ahead). The other options are ignored."`
+}
 
-func (WeatherTool) Name() string { return "get_weather" }
-func (WeatherTool) Description() string {
-	return "Get weather at the given location (and optional forecast)"
+// NewWeatherTool answers with the weather of provider. When provider is also a weather.Geocoder, locations
+// are resolved first: ambiguous ones are handed back to the model with their candidates, and resolved
+// ones are remembered for the rest of the conversation. Values are formatted with the preferences of the
+// user. Results are not cached by the registry since they depend on the conversation. places may be nil.
+func NewWeatherTool(provider weather.Provider, places *Places) Tool {
+	return Typed("get_weather", "Get weather at the given location (and optional forecast)",
+		func(ctx context.Context, args weatherArgs) (string, error) {
+			return getWeather(ctx, provider, places, args)
+		})
 }
-func (WeatherTool) Parameters() openai.FunctionParameters {
-	return openai.FunctionParameters{
-		"type": "object",
-		"properties": map[string]any{
-			"location": map[string]string{
-				"type": "string",
-			},
-			"days": map[string]any{
-				"type":        "integer",
-				"description": "Optional: number of forecast days (1-10)",
-				"minimum":     0,
-				"maximum":     10,
-			},
-		},
-		"required": []string{"location"},
+
+func getWeather(ctx context.Context, provider weather.Provider, places *Places, args weatherArgs) (string, error) {
+	if strings.TrimSpace(args.Location) == "" {
+		return "", fmt.Errorf(`invalid arguments: provide {"location":"<city>", "days":<optional int>}`)
 	}
-}
 
-func (WeatherTool) Call(ctx context.Context, rawArgs string) (string, error) {
-	var args struct {
-		Location string `json:"location"`
-		Days     int    `json:"days,omitempty"`
+	q := weather.Query{
+		Location:   args.Location,
+		Days:       args.Days,
+		Hours:      args.Hours,
+		Alerts:     args.IncludeAlerts,
+		AirQuality: args.IncludeAQI,
 	}
-	if err := json.Unmarshal([]byte(rawArgs), &args); err != nil {
-		return "", fmt.Errorf("invalid arguments: %w", err)
+	if args.Date != "" {
+		date, err := time.Parse(time.DateOnly, args.Date)
+		if err != nil {
+			return "", fmt.Errorf("invalid date %q, use YYYY-MM-DD", args.Date)
+		}
+		q = weather.Query{Location: args.Location, Date: date}
 	}
-	if strings.TrimSpace(args.Location) == "" {
-		return "", fmt.Errorf(`invalid arguments: provide {"location":"<city>", "days":<optional int>}`)
+
+	resolved := resolvePlace(ctx, provider, places, args.Location)
+	if resolved.Place == nil && len(resolved.Candidates) > 0 {
+		return ambiguousLocation(args.Location, resolved.Candidates), nil
 	}
+	q.Place = resolved.Place
 
-	res, err := weather.Fetch(ctx, args.Location, args.Days)
+	res, err := provider.Fetch(ctx, q)
 	if err != nil {
 		return "", err
 	}
 
+	prefs := PreferencesFrom(ctx)
 	var b strings.Builder
-	fmt.Fprintf(&b, "Location: %s, %s\n", res.Place.Name, res.Place.Country)
-	fmt.Fprintf(&b, "Current: %.1f°C, %s, wind %.0f km/h (dir %.0f°)\n",
-		res.Current.TemperatureC, res.Current.Condition, res.Current.WindSpeedKmh, res.Current.WindDirDeg)
+	fmt.Fprintf(&b, "Location: %s\n", res.Place.Label())
+	if len(resolved.Candidates) > 1 {
+		labels := make([]string, 0, len(resolved.Candidates)-1)
+		for _, c := range resolved.Candidates[1:] {
+			labels = append(labels, c.Label())
+		}
+		fmt.Fprintf(&b, "Other places with this name: %s\n", strings.Join(labels, "; "))
+	}
+	if !q.Date.IsZero() {
+		writeDay(&b, prefs, res)
+		return b.String(), nil
+	}
+	fmt.Fprintf(&b, "Current: %s, %s, wind %s (dir %.0f°)\n",
+		prefs.Temperature(res.Current.TemperatureC), res.Current.Condition, prefs.Speed(res.Current.WindSpeedKmh), res.Current.WindDirDeg)
 
 	if len(res.Forecast) > 0 {
 		fmt.Fprintf(&b, "Forecast (%d days):\n", len(res.Forecast))
 		for _, d := range res.Forecast {
-			fmt.Fprintf(&b, "- %s: %s, min %.1f°C / max %.1f°C, wind max %.0f km/h\n",
-				d.Date.Format("2006-01-02"), d.Condition, d.MinTempC, d.MaxTempC, d.WindMaxKmh)
+			fmt.Fprintf(&b, "- %s: %s, min %s / max %s, wind max %s\n",
+				prefs.Date(d.Date), d.Condition, prefs.Temperature(d.MinTempC), prefs.Temperature(d.MaxTempC), prefs.Speed(d.WindMaxKmh))
+		}
+	}
+
+	if len(res.Hourly) > 0 {
+		fmt.Fprintf(&b, "Next %d hours (%s):\n", len(res.Hourly), timesIn(prefs))
+		for _, h := range res.Hourly {
+			fmt.Fprintf(&b, "- %s: %s, %s, %d%% chance of rain (%s), wind %s\n",
+				prefs.DateTime(userTime(prefs, h.Time)), prefs.Temperature(h.TempC), h.Condition, h.ChanceOfRain, prefs.Precipitation(h.PrecipMM), prefs.Speed(h.WindKmh))
+		}
+	}
+
+	if args.IncludeAlerts {
+		switch {
+		case slices.Contains(res.Unsupported, "alerts"):
+			fmt.Fprintf(&b, "Alerts: not available from %s\n", res.Provider)
+		case len(res.Alerts) == 0:
+			b.WriteString("Alerts: none in effect\n")
+		default:
+			b.WriteString("Alerts:\n")
+			for _, a := range res.Alerts {
+				writeAlert(&b, prefs, a)
+			}
+		}
+	}
+
+	if args.IncludeAQI {
+		if aq := res.AirQuality; aq != nil {
+			index := ""
+			if aq.USAQI > 0 {
+				index = fmt.Sprintf(" (US AQI %d)", aq.USAQI)
+			}
+			fmt.Fprintf(&b, "Air quality: %s%s, PM2.5 %.1f µg/m³, PM10 %.1f µg/m³, O3 %.1f µg/m³, NO2 %.1f µg/m³\n",
+				aq.Category, index, aq.PM25, aq.PM10, aq.O3, aq.NO2)
+		} else {
+			fmt.Fprintf(&b, "Air quality: not available from %s\n", res.Provider)
 		}
 	}
 
 	return b.String(), nil
 }
+
+// resolvePlace returns the remembered or resolved place of location, or only candidates when it is
+// ambiguous. Without a geocoder, or when it fails, the provider gets the location as it is.
+func resolvePlace(ctx context.Context, provider weather.Provider, places *Places, location string) weather.Resolution {
+	conversationID := ConversationID(ctx)
+	if place, ok := places.get(ctx, conversationID, location); ok {
+		return weather.Resolution{Place: &place}
+	}
+
+	geocoder, ok := provider.(weather.Geocoder)
+	if !ok {
+		return weather.Resolution{}
+	}
+	res, err := weather.Resolve(ctx, geocoder, location)
+	if err != nil {
+		if !errors.Is(err, weather.ErrNoGeocoder) {
+			slog.WarnContext(ctx, "Location could not be resolved", "location", location, "error", err)
+		}
+		return weather.Resolution{}
+	}
+	if res.Place != nil {
+		places.remember(ctx, conversationID, location, *res.Place)
+	}
+	return res
+}
+
+func ambiguousLocation(location string, candidates []weather.Geocode) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Several places match %q. Ask the user which one they mean, then call get_weather again with its location:\n", location)
+	for _, c := range candidates {
+		fmt.Fprintf(&b, "- %s (lat %.2f, lon %.2f", c.Label(), c.Lat, c.Lon)
+		if c.Population > 0 {
+			fmt.Fprintf(&b, ", population %d", c.Population)
+		}
+		b.WriteString(")\n")
+	}
+	return b.String()
+}
+
+func writeDay(b *strings.Builder, prefs *model.Preferences, res weather.Result) {
+	for _, d := range res.Forecast {
+		fmt.Fprintf(b, "Weather on %s (%s data): %s, min %s / max %s, wind max %s, precipitation %s\n",
+			prefs.Date(d.Date), res.Kind, d.Condition, prefs.Temperature(d.MinTempC), prefs.Temperature(d.MaxTempC),
+			prefs.Speed(d.WindMaxKmh), prefs.Precipitation(d.PrecipMM))
+	}
+	if res.Note != "" {
+		fmt.Fprintf(b, "Note: %s\n", res.Note)
+	}
+}
+
+// userTime converts t to the time zone of the user when they chose one, otherwise it stays in the time
+// zone of the place.
+func userTime(prefs *model.Preferences, t time.Time) time.Time {
+	if prefs == nil || prefs.Timezone == "" {
+		return t
+	}
+	return t.In(prefs.Location())
+}
+
+// timesIn says which time zone userTime gives times in.
+func timesIn(prefs *model.Preferences) string {
+	if prefs == nil || prefs.Timezone == "" {
+		return "local time of the place"
+	}
+	return "times in " + prefs.Timezone
+}
+
+func writeAlert(b *strings.Builder, prefs *model.Preferences, a weather.Alert) {
+	title := a.Event
+	if title == "" {
+		title = a.Headline
+	}
+	fmt.Fprintf(b, "- %s", title)
+	if a.Severity != "" {
+		fmt.Fprintf(b, " (%s)", a.Severity)
+	}
+	if !a.Expires.IsZero() {
+		expires := userTime(prefs, a.Expires)
+		fmt.Fprintf(b, " until %s %s", prefs.DateTime(expires), expires.Format("MST"))
+	}
+	if a.Areas != "" {
+		fmt.Fprintf(b, ", areas: %s", a.Areas)
+	}
+	b.WriteString("\n")
+}
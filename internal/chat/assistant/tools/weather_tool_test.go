@@ -5,36 +5,25 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
-	"os"
 	"strings"
 	"testing"
 
 	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/assistant/tools"
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/model"
 	"github.com/matteo-nyapa/tech-challenge-acai/internal/weather"
 	"github.com/stretchr/testify/require"
 )
 
-func withWeatherServer(t *testing.T, handler http.HandlerFunc) (baseURL string, restore func()) {
+func weatherTool(t *testing.T, handler http.HandlerFunc) tools.Tool {
 	t.Helper()
 	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
 
-	weather.SetBaseURL(srv.URL)
-	weather.SetHTTPClient(srv.Client())
-
-	oldKey := os.Getenv("WEATHER_API_KEY")
-	_ = os.Setenv("WEATHER_API_KEY", "fake-key")
-
-	restore = func() {
-		srv.Close()
-		weather.SetBaseURL("https://api.weatherapi.com/v1")
-		weather.SetHTTPClient(nil)
-		_ = os.Setenv("WEATHER_API_KEY", oldKey)
-	}
-	return srv.URL, restore
+	return tools.NewWeatherTool(weather.NewWeatherAPI("fake-key", weather.WithBaseURL(srv.URL), weather.WithHTTPClient(srv.Client())), nil)
 }
 
 func TestWeatherTool_Current(t *testing.T) {
-	_, restore := withWeatherServer(t, func(w http.ResponseWriter, r *http.Request) {
+	wt := weatherTool(t, func(w http.ResponseWriter, r *http.Request) {
 		require.Contains(t, r.URL.Path, "/current.json")
 		_ = json.NewEncoder(w).Encode(map[string]any{
 			"location": map[string]any{
@@ -51,9 +40,7 @@ func TestWeatherTool_Current(t *testing.T) {
 			},
 		})
 	})
-	defer restore()
 
-	var wt tools.WeatherTool
 	out, err := wt.Call(context.Background(), `{"location":"Barcelona"}`)
 	require.NoError(t, err)
 	require.Contains(t, out, "Barcelona")
@@ -62,7 +49,7 @@ func TestWeatherTool_Current(t *testing.T) {
 }
 
 func TestWeatherTool_Forecast(t *testing.T) {
-	_, restore := withWeatherServer(t, func(w http.ResponseWriter, r *http.Request) {
+	wt := weatherTool(t, func(w http.ResponseWriter, r *http.Request) {
 		require.Contains(t, r.URL.Path, "/forecast.json")
 		require.Equal(t, "3", r.URL.Query().Get("days"))
 		_ = json.NewEncoder(w).Encode(map[string]any{
@@ -102,9 +89,7 @@ func TestWeatherTool_Forecast(t *testing.T) {
 			},
 		})
 	})
-	defer restore()
 
-	var wt tools.WeatherTool
 	out, err := wt.Call(context.Background(), `{"location":"Madrid","days":3}`)
 	require.NoError(t, err)
 	require.Contains(t, out, "Madrid")
@@ -113,8 +98,194 @@ func TestWeatherTool_Forecast(t *testing.T) {
 	require.True(t, strings.Contains(out, "2025-01-01") || strings.Contains(out, "2025-01-02"))
 }
 
+func TestWeatherTool_Preferences(t *testing.T) {
+	wt := weatherTool(t, func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"location": map[string]any{"name": "Boston", "country": "United States of America"},
+			"current": map[string]any{
+				"temp_c":      20.0,
+				"wind_kph":    16.09344,
+				"wind_degree": 90,
+				"condition":   map[string]any{"text": "Clear"},
+			},
+			"forecast": map[string]any{
+				"forecastday": []any{
+					map[string]any{
+						"date": "2025-01-02",
+						"day": map[string]any{
+							"maxtemp_c":   0.0,
+							"mintemp_c":   -10.0,
+							"maxwind_kph": 32.18688,
+							"condition":   map[string]any{"text": "Snow"},
+						},
+					},
+				},
+			},
+		})
+	})
+
+	ctx := tools.WithPreferences(context.Background(), &model.Preferences{Locale: "en-US"})
+	out, err := wt.Call(ctx, `{"location":"Boston","days":1}`)
+	require.NoError(t, err)
+	require.Contains(t, out, "Current: 68.0°F, Clear, wind 10 mph")
+	require.Contains(t, out, "- 01/02/2025: Snow, min 14.0°F / max 32.0°F, wind max 20 mph")
+
+	ctx = tools.WithPreferences(context.Background(), &model.Preferences{Units: model.UnitsMetric, Locale: "en-US"})
+	out, err = wt.Call(ctx, `{"location":"Boston","days":1}`)
+	require.NoError(t, err)
+	require.Contains(t, out, "- 01/02/2025: Snow, min -10.0°C / max 0.0°C, wind max 32 km/h")
+}
+
 func TestWeatherTool_InvalidArgs(t *testing.T) {
-	var wt tools.WeatherTool
+	wt := tools.NewWeatherTool(weather.NewOpenMeteo(), nil)
 	_, err := wt.Call(context.Background(), `{}`)
 	require.Error(t, err)
 }
+
+func TestWeatherTool_HourlyAlertsAndAirQuality(t *testing.T) {
+	wt := weatherTool(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		require.Contains(t, r.URL.Path, "/forecast.json")
+		require.Equal(t, "yes", q.Get("alerts"))
+		require.Equal(t, "yes", q.Get("aqi"))
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"location": map[string]any{"name": "Valencia", "country": "Spain", "tz_id": "Europe/Madrid", "localtime": "2025-10-29 13:40"},
+			"current": map[string]any{
+				"temp_c":    21.0,
+				"condition": map[string]any{"text": "Heavy rain"},
+				"air_quality": map[string]any{
+					"pm2_5": 8.4, "pm10": 12.1, "o3": 60.0, "no2": 9.5, "us-epa-index": 1,
+				},
+			},
+			"forecast": map[string]any{"forecastday": []any{map[string]any{
+				"date": "2025-10-29",
+				"hour": []any{
+					map[string]any{"time": "2025-10-29 12:00", "temp_c": 20.0, "condition": map[string]any{"text": "Rain"}},
+					map[string]any{"time": "2025-10-29 13:00", "temp_c": 21.0, "chance_of_rain": 95, "precip_mm": 12.5, "wind_kph": 30.0, "condition": map[string]any{"text": "Heavy rain"}},
+					map[string]any{"time": "2025-10-29 14:00", "temp_c": 20.5, "chance_of_rain": 90, "precip_mm": 8.0, "wind_kph": 28.0, "condition": map[string]any{"text": "Heavy rain"}},
+					map[string]any{"time": "2025-10-29 15:00", "temp_c": 20.0, "condition": map[string]any{"text": "Rain"}},
+				},
+			}}},
+			"alerts": map[string]any{"alert": []any{map[string]any{
+				"event":    "Red warning for rain",
+				"severity": "Extreme",
+				"areas":    "Valencia coast",
+				"expires":  "2025-10-29T23:59:00+01:00",
+			}}},
+		})
+	})
+
+	out, err := wt.Call(context.Background(), `{"location":"Valencia","hours":2,"include_alerts":true,"include_aqi":true}`)
+	require.NoError(t, err)
+	require.NotContains(t, out, "Forecast (")
+	require.Contains(t, out, "Next 2 hours (local time of the place):")
+	require.Contains(t, out, "- 2025-10-29 13:00: 21.0°C, Heavy rain, 95% chance of rain (12.5 mm), wind 30 km/h")
+	require.NotContains(t, out, "12:00")
+	require.NotContains(t, out, "15:00")
+	require.Contains(t, out, "- Red warning for rain (Extreme) until 2025-10-29 23:59")
+	require.Contains(t, out, "areas: Valencia coast")
+	require.Contains(t, out, "Air quality: Good, PM2.5 8.4 µg/m³")
+
+	ctx := tools.WithPreferences(context.Background(), &model.Preferences{Timezone: "America/New_York"})
+	out, err = wt.Call(ctx, `{"location":"Valencia","hours":2,"include_alerts":true,"include_aqi":true}`)
+	require.NoError(t, err)
+	require.Contains(t, out, "Next 2 hours (times in America/New_York):")
+	require.Contains(t, out, "- 2025-10-29 08:00: 21.0°C, Heavy rain")
+	require.Contains(t, out, "- Red warning for rain (Extreme) until 2025-10-29 18:59 EDT")
+}
+
+func TestWeatherTool_SectionsNotAvailable(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/search":
+			_ = json.NewEncoder(w).Encode(map[string]any{"results": []any{map[string]any{"name": "Lisbon", "country": "Portugal"}}})
+		case "/v1/forecast":
+			_ = json.NewEncoder(w).Encode(map[string]any{"current": map[string]any{"temperature_2m": 19.4}})
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	wt := tools.NewWeatherTool(weather.NewOpenMeteo(weather.WithBaseURL(srv.URL), weather.WithHTTPClient(srv.Client())), nil)
+
+	out, err := wt.Call(context.Background(), `{"location":"Lisbon","include_alerts":true,"include_aqi":true}`)
+	require.NoError(t, err)
+	require.Contains(t, out, "Alerts: not available from openmeteo")
+	require.Contains(t, out, "Air quality: not available from openmeteo")
+}
+
+func TestWeatherTool_Date(t *testing.T) {
+	wt := weatherTool(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/history.json", r.URL.Path)
+		require.Equal(t, "2025-03-04", r.URL.Query().Get("dt"))
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"location": map[string]any{"name": "Rome", "country": "Italy"},
+			"forecast": map[string]any{"forecastday": []any{map[string]any{
+				"date": "2025-03-04",
+				"day": map[string]any{
+					"maxtemp_c": 15.2, "mintemp_c": 6.1, "maxwind_kph": 18.0, "totalprecip_mm": 3.4,
+					"condition": map[string]any{"text": "Light rain"},
+				},
+			}}},
+		})
+	})
+
+	out, err := wt.Call(context.Background(), `{"location":"Rome","date":"2025-03-04","days":3}`)
+	require.NoError(t, err)
+	require.Contains(t, out, "Weather on 2025-03-04 (observed data): Light rain, min 6.1°C / max 15.2°C, wind max 18 km/h, precipitation 3.4 mm")
+	require.NotContains(t, out, "Current")
+
+	_, err = wt.Call(context.Background(), `{"location":"Rome","date":"last tuesday"}`)
+	require.ErrorContains(t, err, "YYYY-MM-DD")
+}
+
+func TestWeatherTool_AmbiguousLocation(t *testing.T) {
+	var searches, forecasts []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/search":
+			searches = append(searches, r.URL.Query().Get("name"))
+			_ = json.NewEncoder(w).Encode(map[string]any{"results": []any{
+				map[string]any{"name": "Santiago", "admin1": "Santiago Metropolitan", "country": "Chile", "country_code": "CL",
+					"latitude": -33.45694, "longitude": -70.64827, "population": 4837295},
+				map[string]any{"name": "Santiago", "admin1": "Santiago", "country": "Dominican Republic", "country_code": "DO",
+					"latitude": 19.4517, "longitude": -70.69703, "population": 3000000},
+			}})
+		case "/v1/forecast":
+			forecasts = append(forecasts, r.URL.Query().Get("latitude"))
+			_ = json.NewEncoder(w).Encode(map[string]any{"current": map[string]any{"temperature_2m": 12.0}})
+		}
+	}))
+	t.Cleanup(srv.Close)
+	provider := weather.NewOpenMeteo(weather.WithBaseURL(srv.URL), weather.WithHTTPClient(srv.Client()))
+	places := tools.NewPlaces(tools.NewLRUCache(10))
+	wt := tools.NewWeatherTool(provider, places)
+	ctx := tools.WithConversation(context.Background(), "conversation-1")
+
+	out, err := wt.Call(ctx, `{"location":"Santiago"}`)
+	require.NoError(t, err)
+	require.Contains(t, out, `Several places match "Santiago". Ask the user`)
+	require.Contains(t, out, "- Santiago, Santiago Metropolitan, Chile (lat -33.46, lon -70.65, population 4837295)")
+	require.Contains(t, out, "- Santiago, Dominican Republic (lat 19.45, lon -70.70, population 3000000)")
+	require.Empty(t, forecasts)
+
+	out, err = wt.Call(ctx, `{"location":"Santiago, Chile"}`)
+	require.NoError(t, err)
+	require.Contains(t, out, "Location: Santiago, Santiago Metropolitan, Chile")
+	require.Equal(t, []string{"-33.4569"}, forecasts)
+
+	// the conversation now means the one in Chile, without searching again
+	out, err = wt.Call(ctx, `{"location":"santiago"}`)
+	require.NoError(t, err)
+	require.Contains(t, out, "Location: Santiago, Santiago Metropolitan, Chile")
+	require.Equal(t, []string{"Santiago", "Santiago"}, searches)
+
+	// compare_weather shares the places it is given, the stub has no daily data so the errors name the place
+	_, err = tools.NewCompareWeatherTool(provider, places).Call(ctx, `{"locations":["santiago","Santiago, Chile"],"from":"2025-10-29"}`)
+	require.ErrorContains(t, err, "Santiago, Santiago Metropolitan, Chile: openmeteo")
+	require.Equal(t, []string{"Santiago", "Santiago"}, searches)
+
+	out, err = wt.Call(tools.WithConversation(context.Background(), "conversation-2"), `{"location":"Santiago"}`)
+	require.NoError(t, err)
+	require.Contains(t, out, "Several places match")
+}
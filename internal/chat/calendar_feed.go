@@ -0,0 +1,71 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	ics "github.com/arran4/golang-ical"
+	"github.com/gorilla/mux"
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/model"
+	"github.com/twitchtv/twirp"
+)
+
+type CalendarEventLister interface {
+	ListCalendarEvents(ctx context.Context, conversationID, token string) ([]*model.CalendarEvent, error)
+}
+
+// CalendarFeed serves the events created in a conversation as a subscribable ICS calendar, it expects
+// the conversation_id route variable and the token of the feed in the query. Unknown conversations and
+// wrong tokens get a 404.
+func CalendarFeed(events CalendarEventLister) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := mux.Vars(r)["conversation_id"]
+
+		items, err := events.ListCalendarEvents(r.Context(), id, r.URL.Query().Get("token"))
+		if err != nil {
+			var twerr twirp.Error
+			if errors.As(err, &twerr) && twerr.Code() == twirp.NotFound {
+				http.NotFound(w, r)
+				return
+			}
+			slog.ErrorContext(r.Context(), "Failed to list calendar events", "conversation_id", id, "error", err)
+			http.Error(w, "failed to load calendar", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+		_ = buildCalendar(id, items).SerializeTo(w)
+	})
+}
+
+func buildCalendar(conversationID string, items []*model.CalendarEvent) *ics.Calendar {
+	cal := ics.NewCalendar()
+	cal.SetMethod(ics.MethodPublish)
+	cal.SetProductId("-//acai//assistant//EN")
+	cal.SetName("Acai " + conversationID)
+	cal.SetXWRCalName("Acai " + conversationID)
+	cal.SetRefreshInterval("PT1H")
+
+	for _, item := range items {
+		event := cal.AddEvent(item.ID.Hex() + "@acai")
+		event.SetDtStampTime(item.CreatedAt)
+		event.SetCreatedTime(item.CreatedAt)
+		if item.AllDay {
+			event.SetAllDayStartAt(item.Start)
+			event.SetAllDayEndAt(item.End)
+		} else {
+			event.SetStartAt(item.Start)
+			event.SetEndAt(item.End)
+		}
+		event.SetSummary(item.Title)
+		if item.Location != "" {
+			event.SetLocation(item.Location)
+		}
+		if item.Description != "" {
+			event.SetDescription(item.Description)
+		}
+	}
+	return cal
+}
@@ -0,0 +1,83 @@
+package chat
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/model"
+	"github.com/twitchtv/twirp"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type eventsStub map[string][]*model.CalendarEvent
+
+// ListCalendarEvents accepts "secret" as the token of the conversations of the stub.
+func (s eventsStub) ListCalendarEvents(ctx context.Context, conversationID, token string) ([]*model.CalendarEvent, error) {
+	items, ok := s[conversationID]
+	if !ok || token != "secret" {
+		return nil, twirp.NotFoundError("calendar not found")
+	}
+	return items, nil
+}
+
+func TestCalendarFeed(t *testing.T) {
+	conv := primitive.NewObjectID()
+	created := time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+	events := eventsStub{conv.Hex(): {
+		{
+			ID: primitive.NewObjectID(), ConversationID: conv, Title: "Flight VY1234 BCN → LHR", Location: "BCN T1",
+			Start: time.Date(2025, 10, 3, 7, 30, 0, 0, time.UTC), End: time.Date(2025, 10, 3, 9, 45, 0, 0, time.UTC),
+			CreatedAt: created,
+		},
+		{
+			ID: primitive.NewObjectID(), ConversationID: conv, Title: "London trip", AllDay: true,
+			Start: time.Date(2025, 10, 3, 0, 0, 0, 0, time.UTC), End: time.Date(2025, 10, 6, 0, 0, 0, 0, time.UTC),
+			CreatedAt: created,
+		},
+	}}
+
+	router := mux.NewRouter()
+	router.Handle("/calendars/{conversation_id}.ics", CalendarFeed(events))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/calendars/"+conv.Hex()+".ics?token=secret", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/calendar") {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+
+	body := rec.Body.String()
+	for _, want := range []string{
+		"BEGIN:VCALENDAR",
+		"SUMMARY:Flight VY1234 BCN → LHR",
+		"DTSTART:20251003T073000Z",
+		"DTEND:20251003T094500Z",
+		"LOCATION:BCN T1",
+		"DTSTART;VALUE=DATE:20251003",
+		"DTEND;VALUE=DATE:20251006",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("feed does not contain %q:\n%s", want, body)
+		}
+	}
+
+	for _, path := range []string{
+		"/calendars/" + conv.Hex() + ".ics",
+		"/calendars/" + conv.Hex() + ".ics?token=guess",
+		"/calendars/" + primitive.NewObjectID().Hex() + ".ics?token=secret",
+		"/calendars/nope.ics?token=secret",
+	} {
+		rec = httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("expected 404 for %s, got %d", path, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package model
+
+import (
+	"context"
+	"crypto/rand"
+	"time"
+
+	"github.com/twitchtv/twirp"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	calendarEventCollection = "calendar_events"
+	calendarFeedCollection  = "calendar_feeds"
+)
+
+// CalendarEvent is an event the assistant added to the calendar of a conversation.
+type CalendarEvent struct {
+	ID             primitive.ObjectID `bson:"_id"`
+	ConversationID primitive.ObjectID `bson:"conversation_id"`
+	Title          string             `bson:"title"`
+	Description    string             `bson:"description,omitempty"`
+	Location       string             `bson:"location,omitempty"`
+	// Start and End of the event, End is exclusive. All-day events span whole days from Start to End.
+	Start     time.Time `bson:"start"`
+	End       time.Time `bson:"end"`
+	AllDay    bool      `bson:"all_day"`
+	CreatedAt time.Time `bson:"created_at"`
+}
+
+func (r *Repository) CreateCalendarEvent(ctx context.Context, e *CalendarEvent) error {
+	_, err := r.conn.Collection(calendarEventCollection).InsertOne(ctx, e)
+	return err
+}
+
+// CalendarFeedToken returns the secret token of the ICS feed of a conversation, it is created on first
+// use.
+func (r *Repository) CalendarFeedToken(ctx context.Context, conversationID primitive.ObjectID) (string, error) {
+	var feed struct {
+		Token string `bson:"token"`
+	}
+	err := r.conn.Collection(calendarFeedCollection).FindOneAndUpdate(ctx,
+		bson.M{"_id": conversationID},
+		bson.M{"$setOnInsert": bson.M{"token": rand.Text()}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)).Decode(&feed)
+	return feed.Token, err
+}
+
+// ListCalendarEvents returns the events of a conversation sorted by start. It fails with a not found
+// error unless token is the one of the feed of the conversation.
+func (r *Repository) ListCalendarEvents(ctx context.Context, conversationID, token string) ([]*CalendarEvent, error) {
+	oid, err := primitive.ObjectIDFromHex(conversationID)
+	if err != nil {
+		return nil, twirp.NotFoundError("invalid conversation ID")
+	}
+
+	n, err := r.conn.Collection(calendarFeedCollection).CountDocuments(ctx,
+		bson.M{"_id": oid, "token": token}, options.Count().SetLimit(1))
+	if err != nil {
+		return nil, err
+	}
+	if token == "" || n == 0 {
+		return nil, twirp.NotFoundError("calendar not found")
+	}
+
+	cursor, err := r.conn.Collection(calendarEventCollection).Find(ctx,
+		bson.M{"conversation_id": oid},
+		options.Find().SetSort(bson.D{{Key: "start", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	var items []*CalendarEvent
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -14,13 +14,29 @@ type Conversation struct {
 	CreatedAt time.Time          `bson:"created_at"`
 	UpdatedAt time.Time          `bson:"updated_at"`
 	Messages  []*Message         `bson:"messages"`
+	// Summary caches the last generated summary, it is dropped when new messages arrive
+	Summary *Summary `bson:"summary"`
+	// PendingAction is set while the assistant waits for the user to approve a tool call
+	PendingAction *PendingAction `bson:"pending_action"`
+	// Preferences of the user for units, date formats and time zone, nil for the defaults
+	Preferences *Preferences `bson:"preferences"`
+}
+
+// FreshSummary returns the cached summary if it still covers all the messages.
+func (c *Conversation) FreshSummary() *Summary {
+	if c.Summary == nil || c.Summary.MessageCount != len(c.Messages) {
+		return nil
+	}
+	return c.Summary
 }
 
 func (c *Conversation) Proto() *pb.Conversation {
 	proto := &pb.Conversation{
-		Id:        c.ID.Hex(),
-		Title:     c.Title,
-		Timestamp: timestamppb.New(c.UpdatedAt),
+		Id:            c.ID.Hex(),
+		Title:         c.Title,
+		Timestamp:     timestamppb.New(c.UpdatedAt),
+		PendingAction: c.PendingAction.Proto(),
+		Preferences:   c.Preferences.Proto(),
 	}
 
 	for _, m := range c.Messages {
@@ -9,18 +9,23 @@ import (
 )
 
 type Message struct {
-	ID        primitive.ObjectID `bson:"_id"`
-	Role      Role               `bson:"role"`
-	Content   string             `bson:"content"`
-	CreatedAt time.Time          `bson:"created_at"`
-	UpdatedAt time.Time          `bson:"updated_at"`
+	ID      primitive.ObjectID `bson:"_id"`
+	Role    Role               `bson:"role"`
+	Content string             `bson:"content"`
+	// Suggestions are the follow-up questions proposed with an assistant reply
+	Suggestions []string `bson:"suggestions,omitempty"`
+	// Action is the pending action an assistant reply resumed, with the decision of the user
+	Action    *PendingAction `bson:"action,omitempty"`
+	CreatedAt time.Time      `bson:"created_at"`
+	UpdatedAt time.Time      `bson:"updated_at"`
 }
 
 func (m *Message) Proto() *pb.Conversation_Message {
 	return &pb.Conversation_Message{
-		Id:        m.ID.Hex(),
-		Role:      m.Role.Proto(),
-		Content:   m.Content,
-		Timestamp: timestamppb.New(m.CreatedAt),
+		Id:          m.ID.Hex(),
+		Role:        m.Role.Proto(),
+		Content:     m.Content,
+		Timestamp:   timestamppb.New(m.CreatedAt),
+		Suggestions: m.Suggestions,
 	}
 }
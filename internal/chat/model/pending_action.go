@@ -0,0 +1,51 @@
+package model
+
+import (
+	"time"
+
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/pb"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// PendingAction is a reply paused until the user approves or rejects the tool calls it wants to run.
+type PendingAction struct {
+	ID        primitive.ObjectID `bson:"_id"`
+	ToolCalls []ToolCall         `bson:"tool_calls"`
+	// Transcript is the JSON encoded list of chat completion messages of the paused reply, it ends
+	// with the assistant message requesting ToolCalls and the results of the calls that already ran.
+	Transcript string    `bson:"transcript"`
+	CreatedAt  time.Time `bson:"created_at"`
+	// Approved is the decision of the user, set once the action is resolved.
+	Approved *bool `bson:"approved,omitempty"`
+}
+
+type ToolCall struct {
+	ID        string `bson:"id"`
+	Name      string `bson:"name"`
+	Arguments string `bson:"arguments"`
+}
+
+// Resolve records the decision of the user and returns the action as kept in the history, without the
+// transcript of the paused reply.
+func (p *PendingAction) Resolve(approve bool) *PendingAction {
+	resolved := *p
+	resolved.Transcript = ""
+	resolved.Approved = &approve
+	return &resolved
+}
+
+func (p *PendingAction) Proto() *pb.PendingAction {
+	if p == nil {
+		return nil
+	}
+
+	proto := &pb.PendingAction{
+		Id:        p.ID.Hex(),
+		Timestamp: timestamppb.New(p.CreatedAt),
+	}
+	for _, c := range p.ToolCalls {
+		proto.ToolCalls = append(proto.ToolCalls, &pb.PendingAction_ToolCall{Tool: c.Name, Arguments: c.Arguments})
+	}
+	return proto
+}
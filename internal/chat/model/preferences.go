@@ -0,0 +1,182 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/pb"
+)
+
+const (
+	UnitsMetric   = "metric"
+	UnitsImperial = "imperial"
+)
+
+// Preferences of the user for the presentation of data. The methods work on a nil *Preferences too,
+// which means metric units, ISO dates with a 24-hour clock and UTC.
+type Preferences struct {
+	// Units is UnitsMetric, UnitsImperial or empty to follow the locale.
+	Units string `bson:"units,omitempty"`
+	// Locale is a language with an optional region, e.g. "en-US".
+	Locale   string `bson:"locale,omitempty"`
+	Timezone string `bson:"timezone,omitempty"`
+}
+
+var localePattern = regexp.MustCompile(`^([a-zA-Z]{2,3})(?:[-_]([a-zA-Z]{2}|[0-9]{3}))?$`)
+
+// PreferencesFromProto validates and normalizes p, nil when p is nil or empty.
+func PreferencesFromProto(p *pb.Preferences) (*Preferences, error) {
+	prefs := &Preferences{
+		Units:    strings.ToLower(strings.TrimSpace(p.GetUnits())),
+		Timezone: strings.TrimSpace(p.GetTimezone()),
+	}
+	if *prefs == (Preferences{}) && strings.TrimSpace(p.GetLocale()) == "" {
+		return nil, nil
+	}
+
+	if prefs.Units != "" && prefs.Units != UnitsMetric && prefs.Units != UnitsImperial {
+		return nil, fmt.Errorf("units must be %q or %q", UnitsMetric, UnitsImperial)
+	}
+	if locale := strings.TrimSpace(p.GetLocale()); locale != "" {
+		m := localePattern.FindStringSubmatch(locale)
+		if m == nil {
+			return nil, fmt.Errorf("invalid locale %q, use a language and region like en-US", locale)
+		}
+		prefs.Locale = strings.ToLower(m[1])
+		if m[2] != "" {
+			prefs.Locale += "-" + strings.ToUpper(m[2])
+		}
+	}
+	if prefs.Timezone != "" {
+		if _, err := time.LoadLocation(prefs.Timezone); err != nil {
+			return nil, errors.New("unknown timezone " + prefs.Timezone)
+		}
+	}
+	return prefs, nil
+}
+
+func (p *Preferences) Proto() *pb.Preferences {
+	if p == nil {
+		return nil
+	}
+	return &pb.Preferences{Units: p.Units, Locale: p.Locale, Timezone: p.Timezone}
+}
+
+func (p *Preferences) language() string {
+	if p == nil {
+		return ""
+	}
+	lang, _, _ := strings.Cut(p.Locale, "-")
+	return lang
+}
+
+func (p *Preferences) region() string {
+	if p == nil {
+		return ""
+	}
+	_, region, _ := strings.Cut(p.Locale, "-")
+	return region
+}
+
+// Imperial tells whether to use °F, mph and inches: when asked for, or by default in the US.
+func (p *Preferences) Imperial() bool {
+	if p == nil {
+		return false
+	}
+	if p.Units != "" {
+		return p.Units == UnitsImperial
+	}
+	return slices.Contains([]string{"US", "LR", "MM"}, p.region())
+}
+
+// Location is the time zone of the user, UTC when unknown.
+func (p *Preferences) Location() *time.Location {
+	if p == nil || p.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+func (p *Preferences) Temperature(celsius float64) string {
+	if p.Imperial() {
+		return fmt.Sprintf("%.1f°F", celsius*9/5+32)
+	}
+	return fmt.Sprintf("%.1f°C", celsius)
+}
+
+func (p *Preferences) Speed(kmh float64) string {
+	if p.Imperial() {
+		return fmt.Sprintf("%.0f mph", kmh/1.609344)
+	}
+	return fmt.Sprintf("%.0f km/h", kmh)
+}
+
+func (p *Preferences) Precipitation(mm float64) string {
+	if p.Imperial() {
+		return fmt.Sprintf("%.2f in", mm/25.4)
+	}
+	return fmt.Sprintf("%.1f mm", mm)
+}
+
+// DateLayout is the usual numeric date format of the locale, ISO when unknown.
+func (p *Preferences) DateLayout() string {
+	switch lang := p.language(); {
+	case lang == "":
+		return time.DateOnly
+	case slices.Contains([]string{"US", "PH"}, p.region()):
+		return "01/02/2006"
+	case slices.Contains([]string{"ja", "zh", "ko", "sv", "lt", "hu"}, lang):
+		return time.DateOnly
+	case slices.Contains([]string{"de", "ru", "pl", "cs", "fi", "nb", "tr", "uk"}, lang):
+		return "02.01.2006"
+	case lang == "nl":
+		return "02-01-2006"
+	}
+	return "02/01/2006"
+}
+
+// ClockLayout is the time of day format of the locale, 24-hour unless the region uses a 12-hour clock.
+func (p *Preferences) ClockLayout() string {
+	if slices.Contains([]string{"US", "PH", "CA", "AU", "NZ", "IN"}, p.region()) {
+		return "3:04 PM"
+	}
+	return "15:04"
+}
+
+func (p *Preferences) Date(t time.Time) string {
+	return t.Format(p.DateLayout())
+}
+
+func (p *Preferences) DateTime(t time.Time) string {
+	return t.Format(p.DateLayout() + " " + p.ClockLayout())
+}
+
+// Describe states the preferences for the model, empty when there are none.
+func (p *Preferences) Describe() string {
+	if p == nil || *p == (Preferences{}) {
+		return ""
+	}
+
+	units := "metric units (°C, km/h, mm)"
+	if p.Imperial() {
+		units = "imperial units (°F, mph, inches)"
+	}
+	example := time.Date(2025, time.December, 31, 18, 30, 0, 0, time.UTC)
+	parts := []string{units, fmt.Sprintf("dates and times written like %s", p.DateTime(example))}
+	if p.Locale != "" {
+		parts = append(parts, "the "+p.Locale+" locale")
+	}
+	if p.Timezone != "" {
+		parts = append(parts, "the "+p.Timezone+" time zone")
+	}
+	return "The user prefers " + strings.Join(parts, ", ") + ". Tool results already use these preferences; " +
+		"answer with the same units and formats, give times in the user's time zone and write in the language of the locale unless the user writes in another one."
+}
@@ -0,0 +1,24 @@
+package model
+
+import (
+	"time"
+
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/pb"
+)
+
+type Summary struct {
+	Summary      string   `bson:"summary"`
+	KeyDecisions []string `bson:"key_decisions"`
+	ActionItems  []string `bson:"action_items"`
+	// MessageCount is the number of messages the summary was generated from
+	MessageCount int       `bson:"message_count"`
+	CreatedAt    time.Time `bson:"created_at"`
+}
+
+func (s *Summary) Proto() *pb.SummarizeConversationResponse {
+	return &pb.SummarizeConversationResponse{
+		Summary:      s.Summary,
+		KeyDecisions: s.KeyDecisions,
+		ActionItems:  s.ActionItems,
+	}
+}
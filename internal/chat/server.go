@@ -17,6 +17,9 @@ var _ pb.ChatService = (*Server)(nil)
 type Assistant interface {
 	Title(ctx context.Context, conv *model.Conversation) (string, error)
 	Reply(ctx context.Context, conv *model.Conversation) (string, error)
+	Suggest(ctx context.Context, conv *model.Conversation) ([]string, error)
+	Summarize(ctx context.Context, conv *model.Conversation) (*model.Summary, error)
+	Resume(ctx context.Context, conv *model.Conversation, approve bool) (string, error)
 }
 
 type Server struct {
@@ -47,6 +50,12 @@ func (s *Server) StartConversation(ctx context.Context, req *pb.StartConversatio
 		return nil, twirp.RequiredArgumentError("message")
 	}
 
+	prefs, err := model.PreferencesFromProto(req.GetPreferences())
+	if err != nil {
+		return nil, twirp.InvalidArgumentError("preferences", err.Error())
+	}
+	conversation.Preferences = prefs
+
 	// choose a title
 	title, err := s.assist.Title(ctx, conversation)
 	if err != nil {
@@ -61,13 +70,15 @@ func (s *Server) StartConversation(ctx context.Context, req *pb.StartConversatio
 		return nil, err
 	}
 
-	conversation.Messages = append(conversation.Messages, &model.Message{
+	message := &model.Message{
 		ID:        primitive.NewObjectID(),
 		Role:      model.RoleAssistant,
 		Content:   reply,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
-	})
+	}
+	conversation.Messages = append(conversation.Messages, message)
+	message.Suggestions = s.suggest(ctx, conversation)
 
 	if err := s.repo.CreateConversation(ctx, conversation); err != nil {
 		return nil, err
@@ -77,6 +88,8 @@ func (s *Server) StartConversation(ctx context.Context, req *pb.StartConversatio
 		ConversationId: conversation.ID.Hex(),
 		Title:          conversation.Title,
 		Reply:          reply,
+		Suggestions:    message.Suggestions,
+		PendingAction:  conversation.PendingAction.Proto(),
 	}, nil
 }
 
@@ -89,12 +102,28 @@ func (s *Server) ContinueConversation(ctx context.Context, req *pb.ContinueConve
 		return nil, twirp.RequiredArgumentError("message")
 	}
 
+	prefs, err := model.PreferencesFromProto(req.GetPreferences())
+	if err != nil {
+		return nil, twirp.InvalidArgumentError("preferences", err.Error())
+	}
+
 	conversation, err := s.repo.DescribeConversation(ctx, req.GetConversationId())
 	if err != nil {
 		return nil, err
 	}
 
+	if prefs != nil {
+		conversation.Preferences = prefs
+	}
+
+	if conversation.PendingAction != nil {
+		// a new message instead of an approval means the user moved on
+		slog.InfoContext(ctx, "Dropping pending action", "conversation_id", conversation.ID, "action_id", conversation.PendingAction.ID)
+		conversation.PendingAction = nil
+	}
+
 	conversation.UpdatedAt = time.Now()
+	conversation.Summary = nil
 	conversation.Messages = append(conversation.Messages, &model.Message{
 		ID:        primitive.NewObjectID(),
 		Role:      model.RoleUser,
@@ -108,19 +137,89 @@ func (s *Server) ContinueConversation(ctx context.Context, req *pb.ContinueConve
 		return nil, twirp.InternalErrorWith(err)
 	}
 
-	conversation.Messages = append(conversation.Messages, &model.Message{
+	message := &model.Message{
 		ID:        primitive.NewObjectID(),
 		Role:      model.RoleAssistant,
 		Content:   reply,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
-	})
+	}
+	conversation.Messages = append(conversation.Messages, message)
+	message.Suggestions = s.suggest(ctx, conversation)
+
+	if err := s.repo.UpdateConversation(ctx, conversation); err != nil {
+		return nil, twirp.InternalErrorWith(err)
+	}
+
+	return &pb.ContinueConversationResponse{
+		Reply:         reply,
+		Suggestions:   message.Suggestions,
+		PendingAction: conversation.PendingAction.Proto(),
+	}, nil
+}
+
+func (s *Server) ResolvePendingAction(ctx context.Context, req *pb.ResolvePendingActionRequest) (*pb.ResolvePendingActionResponse, error) {
+	if req.GetConversationId() == "" {
+		return nil, twirp.RequiredArgumentError("conversation_id")
+	}
+
+	if req.GetActionId() == "" {
+		return nil, twirp.RequiredArgumentError("action_id")
+	}
+
+	conversation, err := s.repo.DescribeConversation(ctx, req.GetConversationId())
+	if err != nil {
+		return nil, err
+	}
+
+	if conversation.PendingAction == nil || conversation.PendingAction.ID.Hex() != req.GetActionId() {
+		return nil, twirp.NotFoundError("pending action not found")
+	}
+
+	conversation.UpdatedAt = time.Now()
+	conversation.Summary = nil
+	action := conversation.PendingAction.Resolve(req.GetApprove())
+
+	reply, err := s.assist.Resume(ctx, conversation, req.GetApprove())
+	if err != nil {
+		return nil, twirp.InternalErrorWith(err)
+	}
+
+	message := &model.Message{
+		ID:        primitive.NewObjectID(),
+		Role:      model.RoleAssistant,
+		Content:   reply,
+		Action:    action,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	conversation.Messages = append(conversation.Messages, message)
+	message.Suggestions = s.suggest(ctx, conversation)
 
 	if err := s.repo.UpdateConversation(ctx, conversation); err != nil {
 		return nil, twirp.InternalErrorWith(err)
 	}
 
-	return &pb.ContinueConversationResponse{Reply: reply}, nil
+	return &pb.ResolvePendingActionResponse{
+		Reply:         reply,
+		Suggestions:   message.Suggestions,
+		PendingAction: conversation.PendingAction.Proto(),
+	}, nil
+}
+
+// suggest returns follow-up questions for the last reply, failures only cost the suggestions. There
+// are none while the reply waits for an approval.
+func (s *Server) suggest(ctx context.Context, conversation *model.Conversation) []string {
+	if conversation.PendingAction != nil {
+		return nil
+	}
+
+	suggestions, err := s.assist.Suggest(ctx, conversation)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to generate follow-up suggestions", "error", err)
+		return nil
+	}
+	return suggestions
 }
 
 func (s *Server) ListConversations(ctx context.Context, req *pb.ListConversationsRequest) (*pb.ListConversationsResponse, error) {
@@ -154,3 +253,31 @@ func (s *Server) DescribeConversation(ctx context.Context, req *pb.DescribeConve
 
 	return &pb.DescribeConversationResponse{Conversation: conversation.Proto()}, nil
 }
+
+func (s *Server) SummarizeConversation(ctx context.Context, req *pb.SummarizeConversationRequest) (*pb.SummarizeConversationResponse, error) {
+	if req.GetConversationId() == "" {
+		return nil, twirp.RequiredArgumentError("conversation_id")
+	}
+
+	conversation, err := s.repo.DescribeConversation(ctx, req.GetConversationId())
+	if err != nil {
+		return nil, err
+	}
+
+	if summary := conversation.FreshSummary(); summary != nil {
+		return summary.Proto(), nil
+	}
+
+	summary, err := s.assist.Summarize(ctx, conversation)
+	if err != nil {
+		return nil, twirp.InternalErrorWith(err)
+	}
+
+	conversation.Summary = summary
+	if err := s.repo.UpdateConversation(ctx, conversation); err != nil {
+		// the summary is still valid, it will just be generated again next time
+		slog.ErrorContext(ctx, "Failed to cache conversation summary", "error", err)
+	}
+
+	return summary.Proto(), nil
+}
@@ -2,21 +2,25 @@ package chat
 
 import (
 	"context"
+	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
-	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/assistant"
 	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/model"
 	. "github.com/matteo-nyapa/tech-challenge-acai/internal/chat/testing"
 	"github.com/matteo-nyapa/tech-challenge-acai/internal/pb"
 	"github.com/twitchtv/twirp"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"google.golang.org/protobuf/testing/protocmp"
 )
 
 type assistantStub struct {
-	title string
-	reply string
+	title       string
+	reply       string
+	suggestions []string
+	summaries   *int
 }
 
 func (s assistantStub) Title(ctx context.Context, conv *model.Conversation) (string, error) {
@@ -33,6 +37,30 @@ func (s assistantStub) Reply(ctx context.Context, conv *model.Conversation) (str
 	return "This is a stubbed assistant reply.", nil
 }
 
+func (s assistantStub) Suggest(ctx context.Context, conv *model.Conversation) ([]string, error) {
+	return s.suggestions, nil
+}
+
+func (s assistantStub) Summarize(ctx context.Context, conv *model.Conversation) (*model.Summary, error) {
+	if s.summaries != nil {
+		*s.summaries++
+	}
+	return &model.Summary{
+		Summary:      fmt.Sprintf("Summary of %d messages", len(conv.Messages)),
+		KeyDecisions: []string{"Travel to Barcelona"},
+		ActionItems:  []string{"Pack an umbrella"},
+		MessageCount: len(conv.Messages),
+	}, nil
+}
+
+func (s assistantStub) Resume(ctx context.Context, conv *model.Conversation, approve bool) (string, error) {
+	conv.PendingAction = nil
+	if approve {
+		return "Table booked.", nil
+	}
+	return "Ok, I did not book it.", nil
+}
+
 func TestServer_DescribeConversation(t *testing.T) {
 	ctx := context.Background()
 	srv := NewServer(model.New(ConnectMongo()), nil)
@@ -68,8 +96,9 @@ func TestServer_StartConversation(t *testing.T) {
 
 	// Inyectamos el stub para NO llamar a OpenAI
 	asst := assistantStub{
-		title: "Barcelona weather forecast",
-		reply: "Today in Barcelona: 20°C, sunny (stub).",
+		title:       "Barcelona weather forecast",
+		reply:       "Today in Barcelona: 20°C, sunny (stub).",
+		suggestions: []string{"Show the 5-day forecast", "Will it rain tomorrow?"},
 	}
 
 	store := model.New(ConnectMongo())
@@ -93,6 +122,9 @@ func TestServer_StartConversation(t *testing.T) {
 	if got, want := start.GetReply(), asst.reply; got == "" || got != want {
 		t.Fatalf("reply mismatch: got %q, want %q", got, want)
 	}
+	if got, want := start.GetSuggestions(), asst.suggestions; !cmp.Equal(got, want) {
+		t.Fatalf("suggestions mismatch (-got +want):\n%s", cmp.Diff(got, want))
+	}
 
 	// 3) BONUS de persistencia: recuperar y verificar mensajes
 	desc, err := srv.DescribeConversation(ctx, &pb.DescribeConversationRequest{
@@ -114,11 +146,142 @@ func TestServer_StartConversation(t *testing.T) {
 	if got := c.GetMessages()[1].GetContent(); got != asst.reply {
 		t.Fatalf("assistant message mismatch: got %q want %q", got, asst.reply)
 	}
+	if got := c.GetMessages()[1].GetSuggestions(); !cmp.Equal(got, asst.suggestions) {
+		t.Fatalf("stored suggestions mismatch (-got +want):\n%s", cmp.Diff(got, asst.suggestions))
+	}
+}
+
+func TestServer_SummarizeConversation(t *testing.T) {
+	ctx := context.Background()
+
+	summaries := 0
+	srv := NewServer(model.New(ConnectMongo()), assistantStub{summaries: &summaries})
+
+	t.Run("summary is cached until new messages arrive", WithFixture(func(t *testing.T, f *Fixture) {
+		c := f.CreateConversation()
+
+		first, err := srv.SummarizeConversation(ctx, &pb.SummarizeConversationRequest{ConversationId: c.ID.Hex()})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got, want := first.GetSummary(), "Summary of 1 messages"; got != want {
+			t.Fatalf("summary mismatch: got %q, want %q", got, want)
+		}
+		if got := first.GetActionItems(); len(got) != 1 || got[0] != "Pack an umbrella" {
+			t.Fatalf("unexpected action items: %v", got)
+		}
+
+		second, err := srv.SummarizeConversation(ctx, &pb.SummarizeConversationRequest{ConversationId: c.ID.Hex()})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !cmp.Equal(first, second, protocmp.Transform()) {
+			t.Fatalf("cached summary mismatch (-got +want):\n%s", cmp.Diff(second, first, protocmp.Transform()))
+		}
+		if summaries != 1 {
+			t.Fatalf("expected the summary to be generated once, got %d", summaries)
+		}
+
+		if _, err := srv.ContinueConversation(ctx, &pb.ContinueConversationRequest{ConversationId: c.ID.Hex(), Message: "And tomorrow?"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		third, err := srv.SummarizeConversation(ctx, &pb.SummarizeConversationRequest{ConversationId: c.ID.Hex()})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got, want := third.GetSummary(), "Summary of 3 messages"; got != want {
+			t.Fatalf("summary mismatch after new messages: got %q, want %q", got, want)
+		}
+		if summaries != 2 {
+			t.Fatalf("expected the summary to be regenerated, got %d generations", summaries)
+		}
+	}))
+
+	t.Run("missing conversation id", func(t *testing.T) {
+		_, err := srv.SummarizeConversation(ctx, &pb.SummarizeConversationRequest{})
+		if te, ok := err.(twirp.Error); !ok || te.Code() != twirp.InvalidArgument {
+			t.Fatalf("expected twirp.InvalidArgument error, got %v", err)
+		}
+	})
+}
+
+func TestServer_ResolvePendingAction(t *testing.T) {
+	ctx := context.Background()
+	srv := NewServer(model.New(ConnectMongo()), assistantStub{suggestions: []string{"Add a reminder?"}})
+
+	pending := func(c *model.Conversation) {
+		c.PendingAction = &model.PendingAction{
+			ID:        primitive.NewObjectID(),
+			ToolCalls: []model.ToolCall{{ID: "call_a", Name: "book_table", Arguments: `{"people":2}`}},
+			CreatedAt: time.Now(),
+		}
+	}
+
+	t.Run("approving resumes the reply", WithFixture(func(t *testing.T, f *Fixture) {
+		c := f.CreateConversation(pending)
+
+		described, err := srv.DescribeConversation(ctx, &pb.DescribeConversationRequest{ConversationId: c.ID.Hex()})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := described.GetConversation().GetPendingAction().GetToolCalls(); len(got) != 1 || got[0].GetTool() != "book_table" {
+			t.Fatalf("unexpected pending tool calls: %v", got)
+		}
+
+		resp, err := srv.ResolvePendingAction(ctx, &pb.ResolvePendingActionRequest{
+			ConversationId: c.ID.Hex(),
+			ActionId:       c.PendingAction.ID.Hex(),
+			Approve:        true,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got, want := resp.GetReply(), "Table booked."; got != want {
+			t.Fatalf("reply mismatch: got %q, want %q", got, want)
+		}
+		if resp.GetPendingAction() != nil {
+			t.Fatalf("expected no pending action, got %v", resp.GetPendingAction())
+		}
+
+		stored, err := f.DescribeConversation(ctx, c.ID.Hex())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if stored.PendingAction != nil {
+			t.Fatalf("expected the pending action to be cleared")
+		}
+		if got := len(stored.Messages); got != 2 {
+			t.Fatalf("expected only the reply to be stored, got %d messages", got)
+		}
+		if action := stored.Messages[1].Action; action == nil || action.Approved == nil || !*action.Approved {
+			t.Fatalf("expected the reply to record the approved action, got %+v", action)
+		}
+	}))
+
+	t.Run("unknown action", WithFixture(func(t *testing.T, f *Fixture) {
+		c := f.CreateConversation(pending)
+
+		_, err := srv.ResolvePendingAction(ctx, &pb.ResolvePendingActionRequest{
+			ConversationId: c.ID.Hex(),
+			ActionId:       primitive.NewObjectID().Hex(),
+		})
+		if te, ok := err.(twirp.Error); !ok || te.Code() != twirp.NotFound {
+			t.Fatalf("expected twirp.NotFound error, got %v", err)
+		}
+	}))
+
+	t.Run("missing action id", func(t *testing.T) {
+		_, err := srv.ResolvePendingAction(ctx, &pb.ResolvePendingActionRequest{ConversationId: primitive.NewObjectID().Hex()})
+		if te, ok := err.(twirp.Error); !ok || te.Code() != twirp.InvalidArgument {
+			t.Fatalf("expected twirp.InvalidArgument error, got %v", err)
+		}
+	})
 }
 
 func TestAssistant_Title_GeneratesReadableTitle(t *testing.T) {
 	ctx := context.Background()
-	a := assistant.New()
+	a := NewRecordedAssistant(t, "title_readable")
 
 	conv := &model.Conversation{
 		Messages: []*model.Message{
@@ -144,7 +307,7 @@ func TestAssistant_Title_GeneratesReadableTitle(t *testing.T) {
 
 func TestAssistant_Title_HandlesEmptyMessagesGracefully(t *testing.T) {
 	ctx := context.Background()
-	a := assistant.New()
+	a := NewRecordedAssistant(t, "title_empty_message")
 
 	conv := &model.Conversation{
 		Messages: []*model.Message{
@@ -161,3 +324,54 @@ func TestAssistant_Title_HandlesEmptyMessagesGracefully(t *testing.T) {
 		t.Fatal("expected a fallback title, got empty")
 	}
 }
+
+func TestServer_Preferences(t *testing.T) {
+	ctx := context.Background()
+	srv := NewServer(model.New(ConnectMongo()), assistantStub{})
+
+	t.Run("preferences are stored and replaced", WithFixture(func(t *testing.T, f *Fixture) {
+		start, err := srv.StartConversation(ctx, &pb.StartConversationRequest{
+			Message:     "What is the weather in Boston?",
+			Preferences: &pb.Preferences{Locale: "en_us", Timezone: "America/New_York"},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		desc, err := srv.DescribeConversation(ctx, &pb.DescribeConversationRequest{ConversationId: start.GetConversationId()})
+		if err != nil {
+			t.Fatalf("DescribeConversation failed: %v", err)
+		}
+		want := &pb.Preferences{Locale: "en-US", Timezone: "America/New_York"}
+		if got := desc.GetConversation().GetPreferences(); !cmp.Equal(got, want, protocmp.Transform()) {
+			t.Fatalf("preferences mismatch (-got +want):\n%s", cmp.Diff(got, want, protocmp.Transform()))
+		}
+
+		_, err = srv.ContinueConversation(ctx, &pb.ContinueConversationRequest{
+			ConversationId: start.GetConversationId(),
+			Message:        "And in Celsius?",
+			Preferences:    &pb.Preferences{Units: "metric", Locale: "en-US", Timezone: "America/New_York"},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		desc, err = srv.DescribeConversation(ctx, &pb.DescribeConversationRequest{ConversationId: start.GetConversationId()})
+		if err != nil {
+			t.Fatalf("DescribeConversation failed: %v", err)
+		}
+		if got := desc.GetConversation().GetPreferences().GetUnits(); got != model.UnitsMetric {
+			t.Fatalf("units mismatch: got %q", got)
+		}
+	}))
+
+	t.Run("invalid preferences are rejected", func(t *testing.T) {
+		_, err := srv.StartConversation(ctx, &pb.StartConversationRequest{
+			Message:     "What time is it?",
+			Preferences: &pb.Preferences{Timezone: "Mars/Olympus_Mons"},
+		})
+		if te, ok := err.(twirp.Error); !ok || te.Code() != twirp.InvalidArgument {
+			t.Fatalf("expected twirp.InvalidArgument error, got %v", err)
+		}
+	})
+}
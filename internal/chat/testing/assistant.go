@@ -0,0 +1,48 @@
+package testing
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/cassette"
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/assistant"
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/assistant/calendar"
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/assistant/tools"
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/weather"
+	"github.com/openai/openai-go/v2/option"
+)
+
+// NewRecordedAssistant returns an assistant whose OpenAI, WeatherAPI and calendar traffic goes through the
+// cassette testdata/cassettes/<name>.json. Tests replay it offline by default, run them with
+// CASSETTE_MODE=record (and real API keys) to record it again.
+func NewRecordedAssistant(t *testing.T, name string) *assistant.Assistant {
+	t.Helper()
+
+	mode := cassette.Mode(os.Getenv("CASSETTE_MODE"))
+	if mode == "" || mode == cassette.ModeOff {
+		mode = cassette.ModeReplay
+	}
+
+	c, err := cassette.Open(filepath.Join("testdata", "cassettes", name+".json"), mode)
+	if err != nil {
+		t.Fatalf("failed to open cassette: %v", err)
+	}
+
+	opts := []option.RequestOption{option.WithHTTPClient(c.Client())}
+	if mode == cassette.ModeReplay {
+		opts = append(opts, option.WithAPIKey("replay"))
+		if os.Getenv("WEATHER_API_KEY") == "" {
+			t.Setenv("WEATHER_API_KEY", "replay")
+		}
+	}
+
+	cfg := assistant.ConfigFromEnv()
+	cfg.Providers = assistant.ProvidersFromEnv(opts...)
+	places := tools.NewPlaces(tools.NewLRUCache(1000))
+	cfg.Tools = assistant.DefaultTools(calendar.NewLoader(0, calendar.WithHTTPClient(c.Client())), places)
+	forecasts := weather.FromEnv(weather.WithHTTPClient(c.Client()))
+	cfg.Tools.Register(tools.NewWeatherTool(forecasts, places))
+	cfg.Tools.Register(tools.NewCompareWeatherTool(forecasts, places))
+	return assistant.NewWithConfig(cfg)
+}
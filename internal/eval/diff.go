@@ -0,0 +1,82 @@
+package eval
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// latencyRegression is the relative slowdown of a case reported as a latency regression.
+const latencyRegression = 0.5
+
+type Diff struct {
+	Regressions []string
+	Fixes       []string
+	Slower      []string
+	Added       []string
+	Removed     []string
+	PassRate    [2]float64
+	AvgLatency  [2]int64
+}
+
+// Compare reports the changes of cur against a previous run of the same golden set.
+func Compare(prev, cur Report) Diff {
+	d := Diff{
+		PassRate:   [2]float64{prev.PassRate, cur.PassRate},
+		AvgLatency: [2]int64{prev.AvgLatencyMs, cur.AvgLatencyMs},
+	}
+
+	before := make(map[string]Result, len(prev.Results))
+	for _, r := range prev.Results {
+		before[r.ID] = r
+	}
+
+	seen := map[string]bool{}
+	for _, r := range cur.Results {
+		seen[r.ID] = true
+
+		old, ok := before[r.ID]
+		switch {
+		case !ok:
+			d.Added = append(d.Added, r.ID)
+		case old.Passed && !r.Passed:
+			d.Regressions = append(d.Regressions, r.ID)
+		case !old.Passed && r.Passed:
+			d.Fixes = append(d.Fixes, r.ID)
+		}
+
+		if ok && old.LatencyMs > 0 && float64(r.LatencyMs) > float64(old.LatencyMs)*(1+latencyRegression) {
+			d.Slower = append(d.Slower, fmt.Sprintf("%s (%dms -> %dms)", r.ID, old.LatencyMs, r.LatencyMs))
+		}
+	}
+
+	for id := range before {
+		if !seen[id] {
+			d.Removed = append(d.Removed, id)
+		}
+	}
+	sort.Strings(d.Removed)
+
+	return d
+}
+
+func (d Diff) Print(w io.Writer) {
+	fmt.Fprintf(w, "Pass rate: %.0f%% -> %.0f%%\n", d.PassRate[0]*100, d.PassRate[1]*100)
+	fmt.Fprintf(w, "Avg latency: %dms -> %dms\n", d.AvgLatency[0], d.AvgLatency[1])
+
+	printIDs(w, "Regressions", d.Regressions)
+	printIDs(w, "Fixes", d.Fixes)
+	printIDs(w, "Slower", d.Slower)
+	printIDs(w, "Added", d.Added)
+	printIDs(w, "Removed", d.Removed)
+}
+
+func printIDs(w io.Writer, title string, ids []string) {
+	if len(ids) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "%s:\n", title)
+	for _, id := range ids {
+		fmt.Fprintf(w, "  - %s\n", id)
+	}
+}
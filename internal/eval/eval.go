@@ -0,0 +1,201 @@
+package eval
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/assistant"
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/assistant/tools"
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Case is a golden question with the facts and tool calls expected in the answer.
+type Case struct {
+	ID               string   `json:"id"`
+	Question         string   `json:"question"`
+	ExpectTools      []string `json:"expect_tools,omitempty"`
+	ExpectSubstrings []string `json:"expect_substrings,omitempty"`
+}
+
+type Result struct {
+	ID                string   `json:"id"`
+	Question          string   `json:"question"`
+	Reply             string   `json:"reply"`
+	ToolsCalled       []string `json:"tools_called"`
+	MissingTools      []string `json:"missing_tools,omitempty"`
+	MissingSubstrings []string `json:"missing_substrings,omitempty"`
+	LatencyMs         int64    `json:"latency_ms"`
+	Error             string   `json:"error,omitempty"`
+	Passed            bool     `json:"passed"`
+}
+
+type Report struct {
+	Provider     string    `json:"provider"`
+	StartedAt    time.Time `json:"started_at"`
+	Total        int       `json:"total"`
+	Passed       int       `json:"passed"`
+	PassRate     float64   `json:"pass_rate"`
+	AvgLatencyMs int64     `json:"avg_latency_ms"`
+	Results      []Result  `json:"results"`
+}
+
+func LoadCases(path string) ([]Case, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var cases []Case
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var c Case
+		if err := json.Unmarshal([]byte(line), &c); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, n, err)
+		}
+		if c.ID == "" || strings.TrimSpace(c.Question) == "" {
+			return nil, fmt.Errorf("%s:%d: id and question are required", path, n)
+		}
+		cases = append(cases, c)
+	}
+
+	return cases, scanner.Err()
+}
+
+// Runner answers every case with a fresh assistant built around Tools, recording the tools it calls. The
+// registry is used as it is, so confirmations and the result cache behave as in production.
+type Runner struct {
+	Provider     string
+	Tools        *tools.Registry
+	NewAssistant func(reg *tools.Registry) *assistant.Assistant
+}
+
+func (r Runner) Run(ctx context.Context, cases []Case) Report {
+	report := Report{Provider: r.Provider, StartedAt: time.Now()}
+
+	for _, c := range cases {
+		rec := &recorder{}
+
+		conv := &model.Conversation{
+			ID:        primitive.NewObjectID(),
+			Title:     c.ID,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+			Messages: []*model.Message{{
+				ID:        primitive.NewObjectID(),
+				Role:      model.RoleUser,
+				Content:   c.Question,
+				CreatedAt: time.Now(),
+				UpdatedAt: time.Now(),
+			}},
+		}
+
+		start := time.Now()
+		reply, err := r.NewAssistant(r.Tools).Reply(tools.WithCallObserver(ctx, rec.add), conv)
+		latency := time.Since(start)
+
+		res := Score(c, reply, rec.names())
+		res.LatencyMs = latency.Milliseconds()
+		if err != nil {
+			res.Error = err.Error()
+			res.Passed = false
+		}
+
+		report.Results = append(report.Results, res)
+	}
+
+	report.summarize()
+	return report
+}
+
+// Score checks the reply and the tool calls against the expectations of the case.
+func Score(c Case, reply string, toolsCalled []string) Result {
+	res := Result{
+		ID:          c.ID,
+		Question:    c.Question,
+		Reply:       reply,
+		ToolsCalled: toolsCalled,
+	}
+
+	for _, t := range c.ExpectTools {
+		if !slices.Contains(toolsCalled, t) {
+			res.MissingTools = append(res.MissingTools, t)
+		}
+	}
+
+	lower := strings.ToLower(reply)
+	for _, s := range c.ExpectSubstrings {
+		if !strings.Contains(lower, strings.ToLower(s)) {
+			res.MissingSubstrings = append(res.MissingSubstrings, s)
+		}
+	}
+
+	res.Passed = len(res.MissingTools) == 0 && len(res.MissingSubstrings) == 0
+	return res
+}
+
+func (r *Report) summarize() {
+	r.Total = len(r.Results)
+	r.Passed = 0
+
+	var latency int64
+	for _, res := range r.Results {
+		if res.Passed {
+			r.Passed++
+		}
+		latency += res.LatencyMs
+	}
+
+	if r.Total > 0 {
+		r.PassRate = float64(r.Passed) / float64(r.Total)
+		r.AvgLatencyMs = latency / int64(r.Total)
+	}
+}
+
+func WriteReport(path string, r Report) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+func ReadReport(path string) (Report, error) {
+	var r Report
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return r, err
+	}
+	return r, json.Unmarshal(data, &r)
+}
+
+type recorder struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (r *recorder) add(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, name)
+}
+
+func (r *recorder) names() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.calls)
+}
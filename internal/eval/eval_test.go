@@ -0,0 +1,174 @@
+package eval_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/assistant"
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/assistant/tools"
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/eval"
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/option"
+	"github.com/stretchr/testify/require"
+)
+
+type echoTool struct{}
+
+func (echoTool) Name() string                          { return "get_weather" }
+func (echoTool) Description() string                   { return "Returns the weather" }
+func (echoTool) Parameters() openai.FunctionParameters { return openai.FunctionParameters{} }
+func (echoTool) Call(context.Context, string) (string, error) {
+	return "Sunny, 25C", nil
+}
+
+func completion(message map[string]any) string {
+	b, _ := json.Marshal(map[string]any{
+		"id": "chatcmpl-eval", "object": "chat.completion", "created": 1730000000, "model": "test-model",
+		"choices": []any{map[string]any{"index": 0, "finish_reason": "stop", "message": message}},
+	})
+	return string(b)
+}
+
+// weatherServer asks for the weather tool on the first completion and answers once it has the result.
+func weatherServer(t *testing.T) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Messages []map[string]any `json:"messages"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		w.Header().Set("Content-Type", "application/json")
+		if req.Messages[len(req.Messages)-1]["role"] == "tool" {
+			_, _ = w.Write([]byte(completion(map[string]any{"role": "assistant", "content": "It is sunny in Barcelona."})))
+			return
+		}
+		_, _ = w.Write([]byte(completion(map[string]any{
+			"role": "assistant", "content": nil,
+			"tool_calls": []any{map[string]any{
+				"id": "call_a", "type": "function",
+				"function": map[string]any{"name": "get_weather", "arguments": `{"location":"Barcelona"}`},
+			}},
+		})))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestLoadCases(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cases.jsonl")
+	content := strings.Join([]string{
+		`{"id":"a","question":"Weather in Porto?","expect_tools":["get_weather"],"expect_substrings":["Porto"]}`,
+		``,
+		`# comments are ignored`,
+		`{"id":"b","question":"Hi"}`,
+	}, "\n")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+	cases, err := eval.LoadCases(path)
+	require.NoError(t, err)
+	require.Len(t, cases, 2)
+	require.Equal(t, []string{"get_weather"}, cases[0].ExpectTools)
+	require.Equal(t, "b", cases[1].ID)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"id":"a"}`), 0o644))
+	_, err = eval.LoadCases(path)
+	require.Error(t, err)
+}
+
+func TestScore(t *testing.T) {
+	c := eval.Case{ID: "a", ExpectTools: []string{"get_weather", "get_today_date"}, ExpectSubstrings: []string{"barcelona", "25"}}
+
+	res := eval.Score(c, "It is 25C in Barcelona", []string{"get_weather", "get_today_date"})
+	require.True(t, res.Passed)
+
+	res = eval.Score(c, "It is sunny", []string{"get_weather"})
+	require.False(t, res.Passed)
+	require.Equal(t, []string{"get_today_date"}, res.MissingTools)
+	require.Equal(t, []string{"barcelona", "25"}, res.MissingSubstrings)
+}
+
+func TestRunner_Run_RecordsToolCalls(t *testing.T) {
+	srv := weatherServer(t)
+
+	runner := eval.Runner{
+		Provider: "test",
+		Tools:    tools.NewRegistry(echoTool{}),
+		NewAssistant: func(reg *tools.Registry) *assistant.Assistant {
+			return assistant.NewWithConfig(assistant.Config{
+				Providers: []assistant.Provider{assistant.NewProvider("test", "test-model", option.WithBaseURL(srv.URL), option.WithAPIKey("test"))},
+				Tools:     reg,
+			})
+		},
+	}
+
+	report := runner.Run(context.Background(), []eval.Case{
+		{ID: "weather", Question: "Weather in Barcelona?", ExpectTools: []string{"get_weather"}, ExpectSubstrings: []string{"sunny"}},
+		{ID: "holidays", Question: "Next holiday?", ExpectTools: []string{"get_holidays"}},
+	})
+
+	require.Equal(t, 2, report.Total)
+	require.Equal(t, 1, report.Passed)
+	require.Equal(t, 0.5, report.PassRate)
+	require.Equal(t, []string{"get_weather"}, report.Results[0].ToolsCalled)
+	require.Equal(t, "It is sunny in Barcelona.", report.Results[0].Reply)
+	require.Equal(t, []string{"get_holidays"}, report.Results[1].MissingTools)
+}
+
+type confirmedEcho struct {
+	echoTool
+	calls int
+}
+
+func (*confirmedEcho) RequiresConfirmation() bool { return true }
+
+func (e *confirmedEcho) Call(ctx context.Context, args string) (string, error) {
+	e.calls++
+	return e.echoTool.Call(ctx, args)
+}
+
+func TestRunner_Run_KeepsConfirmations(t *testing.T) {
+	srv := weatherServer(t)
+	tool := &confirmedEcho{}
+
+	runner := eval.Runner{
+		Provider: "test",
+		Tools:    tools.NewRegistry(tool),
+		NewAssistant: func(reg *tools.Registry) *assistant.Assistant {
+			return assistant.NewWithConfig(assistant.Config{
+				Providers: []assistant.Provider{assistant.NewProvider("test", "test-model", option.WithBaseURL(srv.URL), option.WithAPIKey("test"))},
+				Tools:     reg,
+			})
+		},
+	}
+
+	report := runner.Run(context.Background(), []eval.Case{{ID: "weather", Question: "Weather in Barcelona?"}})
+	require.Zero(t, tool.calls, "tools that need an approval must not run unattended")
+	require.Empty(t, report.Results[0].ToolsCalled)
+	require.Contains(t, report.Results[0].Reply, "I need your approval")
+}
+
+func TestCompare(t *testing.T) {
+	prev := eval.Report{PassRate: 0.5, Results: []eval.Result{
+		{ID: "a", Passed: true, LatencyMs: 100},
+		{ID: "b", Passed: false, LatencyMs: 100},
+		{ID: "gone", Passed: true},
+	}}
+	cur := eval.Report{PassRate: 0.5, Results: []eval.Result{
+		{ID: "a", Passed: false, LatencyMs: 300},
+		{ID: "b", Passed: true, LatencyMs: 110},
+		{ID: "new", Passed: true},
+	}}
+
+	d := eval.Compare(prev, cur)
+	require.Equal(t, []string{"a"}, d.Regressions)
+	require.Equal(t, []string{"b"}, d.Fixes)
+	require.Equal(t, []string{"new"}, d.Added)
+	require.Equal(t, []string{"gone"}, d.Removed)
+	require.Equal(t, []string{"a (100ms -> 300ms)"}, d.Slower)
+}
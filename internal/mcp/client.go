@@ -0,0 +1,181 @@
+// Package mcp is a minimal Model Context Protocol client that exposes the tools of external MCP
+// servers as tools.Tool.
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+const protocolVersion = "2025-06-18"
+
+type request struct {
+	JSONRPC string `json:"jsonrpc"`
+	ID      *int64 `json:"id,omitempty"`
+	Method  string `json:"method"`
+	Params  any    `json:"params,omitempty"`
+}
+
+type message struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string { return fmt.Sprintf("mcp error %d: %s", e.Code, e.Message) }
+
+// transport exchanges JSON-RPC messages with a server, call returns the response to req.
+type transport interface {
+	call(ctx context.Context, req request) (message, error)
+	notify(ctx context.Context, req request) error
+	Close() error
+}
+
+type Client struct {
+	name   string
+	t      transport
+	nextID atomic.Int64
+}
+
+// ToolInfo describes a tool as advertised by an MCP server.
+type ToolInfo struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	InputSchema map[string]any `json:"inputSchema"`
+}
+
+// Connect starts (stdio) or reaches (http) the configured server and performs the MCP handshake within
+// the connect timeout of cfg. A stdio server is killed when ctx is done.
+func Connect(ctx context.Context, cfg ServerConfig) (*Client, error) {
+	var (
+		t   transport
+		err error
+	)
+	switch {
+	case cfg.Command != "":
+		t, err = newStdioTransport(ctx, cfg)
+	case cfg.URL != "":
+		t = newHTTPTransport(cfg)
+	default:
+		err = errors.New("either command or url is required")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("mcp server %s: %w", cfg.Name, err)
+	}
+
+	c := &Client{name: cfg.Name, t: t}
+	handshakeCtx, cancel := context.WithTimeout(ctx, cfg.connectTimeout())
+	defer cancel()
+	if err := c.initialize(handshakeCtx); err != nil {
+		_ = t.Close()
+		return nil, fmt.Errorf("mcp server %s: %w", cfg.Name, err)
+	}
+	return c, nil
+}
+
+func (c *Client) Name() string { return c.name }
+
+func (c *Client) Close() error { return c.t.Close() }
+
+func (c *Client) initialize(ctx context.Context) error {
+	err := c.call(ctx, "initialize", map[string]any{
+		"protocolVersion": protocolVersion,
+		"capabilities":    map[string]any{},
+		"clientInfo":      map[string]any{"name": "acai", "version": "1.0.0"},
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("initialize: %w", err)
+	}
+	return c.t.notify(ctx, request{JSONRPC: "2.0", Method: "notifications/initialized"})
+}
+
+// ListTools returns every tool of the server, following pagination.
+func (c *Client) ListTools(ctx context.Context) ([]ToolInfo, error) {
+	var out []ToolInfo
+	cursor := ""
+	for {
+		params := map[string]any{}
+		if cursor != "" {
+			params["cursor"] = cursor
+		}
+
+		var page struct {
+			Tools      []ToolInfo `json:"tools"`
+			NextCursor string     `json:"nextCursor"`
+		}
+		if err := c.call(ctx, "tools/list", params, &page); err != nil {
+			return nil, fmt.Errorf("tools/list: %w", err)
+		}
+		out = append(out, page.Tools...)
+
+		if page.NextCursor == "" {
+			return out, nil
+		}
+		cursor = page.NextCursor
+	}
+}
+
+// CallTool runs a tool and returns its text content. Results flagged as errors by the server are
+// returned as errors.
+func (c *Client) CallTool(ctx context.Context, name string, args json.RawMessage) (string, error) {
+	if len(strings.TrimSpace(string(args))) == 0 {
+		args = json.RawMessage("{}")
+	}
+
+	var res struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+		StructuredContent json.RawMessage `json:"structuredContent"`
+		IsError           bool            `json:"isError"`
+	}
+	if err := c.call(ctx, "tools/call", map[string]any{"name": name, "arguments": args}, &res); err != nil {
+		return "", err
+	}
+
+	var parts []string
+	for _, content := range res.Content {
+		if content.Type == "text" {
+			parts = append(parts, content.Text)
+		}
+	}
+	text := strings.Join(parts, "\n")
+	if text == "" && len(res.StructuredContent) > 0 {
+		text = string(res.StructuredContent)
+	}
+
+	if res.IsError {
+		if text == "" {
+			text = "tool failed"
+		}
+		return "", errors.New(text)
+	}
+	return text, nil
+}
+
+func (c *Client) call(ctx context.Context, method string, params any, out any) error {
+	id := c.nextID.Add(1)
+	resp, err := c.t.call(ctx, request{JSONRPC: "2.0", ID: &id, Method: method, Params: params})
+	if err != nil {
+		return err
+	}
+	if resp.Error != nil {
+		return resp.Error
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(resp.Result, out)
+}
@@ -0,0 +1,67 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+)
+
+// ServerConfig describes how to reach an MCP server: either a Command started with stdio transport
+// or the URL of a streamable HTTP endpoint. Env and Headers values may reference environment
+// variables, e.g. "Bearer ${GITHUB_TOKEN}".
+type ServerConfig struct {
+	Name    string            `json:"-"`
+	Command string            `json:"command,omitempty"`
+	Args    []string          `json:"args,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
+	URL     string            `json:"url,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	// Prefix is prepended to the tool names of the server to avoid clashes with other tools, the server
+	// name and an underscore by default.
+	Prefix string `json:"prefix,omitempty"`
+	// ConnectTimeout bounds the handshake and the tool listing, e.g. "30s" (default 10s).
+	ConnectTimeout string `json:"connect_timeout,omitempty"`
+}
+
+const defaultConnectTimeout = 10 * time.Second
+
+func (c ServerConfig) connectTimeout() time.Duration {
+	if d, err := time.ParseDuration(c.ConnectTimeout); err == nil && d > 0 {
+		return d
+	}
+	return defaultConnectTimeout
+}
+
+func (c ServerConfig) prefix() string {
+	if c.Prefix != "" {
+		return c.Prefix
+	}
+	return c.Name + "_"
+}
+
+// LoadConfig reads a JSON file in the usual MCP client format:
+//
+//	{"mcpServers": {"files": {"command": "mcp-files", "args": ["/data"]}, "search": {"url": "http://localhost:9000/mcp"}}}
+func LoadConfig(path string) ([]ServerConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file struct {
+		Servers map[string]ServerConfig `json:"mcpServers"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	out := make([]ServerConfig, 0, len(file.Servers))
+	for name, s := range file.Servers {
+		s.Name = name
+		out = append(out, s)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
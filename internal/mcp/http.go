@@ -0,0 +1,161 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const sessionHeader = "Mcp-Session-Id"
+
+// httpTransport implements the streamable HTTP transport: every message is POSTed to the server URL
+// and the response comes back either as JSON or as a server sent event stream.
+type httpTransport struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+
+	mu      sync.Mutex
+	session string
+}
+
+func newHTTPTransport(cfg ServerConfig) *httpTransport {
+	headers := make(map[string]string, len(cfg.Headers))
+	for k, v := range cfg.Headers {
+		headers[k] = os.ExpandEnv(v)
+	}
+	return &httpTransport{url: cfg.URL, headers: headers, client: &http.Client{Timeout: 60 * time.Second}}
+}
+
+func (t *httpTransport) call(ctx context.Context, req request) (message, error) {
+	resp, err := t.post(ctx, req)
+	if err != nil {
+		return message{}, err
+	}
+	defer resp.Body.Close()
+
+	id, _ := json.Marshal(req.ID)
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "text/event-stream" {
+		var msg message
+		if err := json.NewDecoder(resp.Body).Decode(&msg); err != nil {
+			return message{}, fmt.Errorf("decode %s response: %w", req.Method, err)
+		}
+		return msg, nil
+	}
+
+	// the stream may carry notifications before the response, wait for the one matching our id
+	var data []string
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if v, ok := strings.CutPrefix(line, "data:"); ok {
+			data = append(data, strings.TrimPrefix(v, " "))
+			continue
+		}
+		if line != "" || len(data) == 0 {
+			continue
+		}
+
+		var msg message
+		err := json.Unmarshal([]byte(strings.Join(data, "\n")), &msg)
+		data = data[:0]
+		if err == nil && msg.Method == "" && bytes.Equal(msg.ID, id) {
+			return msg, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return message{}, err
+	}
+	return message{}, fmt.Errorf("no response to %s in event stream", req.Method)
+}
+
+func (t *httpTransport) notify(ctx context.Context, req request) error {
+	resp, err := t.post(ctx, req)
+	if err != nil {
+		return err
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return resp.Body.Close()
+}
+
+func (t *httpTransport) post(ctx context.Context, req request) (*http.Response, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "application/json, text/event-stream")
+	httpReq.Header.Set("MCP-Protocol-Version", protocolVersion)
+	t.setHeaders(httpReq)
+
+	resp, err := t.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s: unexpected status %d: %s", req.Method, resp.StatusCode, strings.TrimSpace(string(b)))
+	}
+
+	if s := resp.Header.Get(sessionHeader); s != "" {
+		t.mu.Lock()
+		t.session = s
+		t.mu.Unlock()
+	}
+	return resp, nil
+}
+
+func (t *httpTransport) setHeaders(req *http.Request) {
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.session != "" {
+		req.Header.Set(sessionHeader, t.session)
+	}
+}
+
+// Close ends the session on the server, if it gave us one.
+func (t *httpTransport) Close() error {
+	t.mu.Lock()
+	session := t.session
+	t.mu.Unlock()
+	if session == "" {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, t.url, nil)
+	if err != nil {
+		return err
+	}
+	t.setHeaders(req)
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
@@ -0,0 +1,258 @@
+package mcp_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/assistant/tools"
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+// When MCP_TEST_SERVER is set the test binary acts as a tiny stdio MCP server, one that never answers
+// when it is "hang".
+func TestMain(m *testing.M) {
+	switch os.Getenv("MCP_TEST_SERVER") {
+	case "1":
+		serveStdio()
+		return
+	case "hang":
+		_, _ = io.Copy(io.Discard, os.Stdin)
+		return
+	}
+	os.Exit(m.Run())
+}
+
+type rpcMessage struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method"`
+	Params struct {
+		Cursor    string          `json:"cursor"`
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	} `json:"params"`
+}
+
+// handle answers a request of the test server, it returns nil for notifications.
+func handle(msg rpcMessage) map[string]any {
+	if len(msg.ID) == 0 {
+		return nil
+	}
+
+	var result any
+	switch msg.Method {
+	case "initialize":
+		result = map[string]any{
+			"protocolVersion": "2025-06-18",
+			"capabilities":    map[string]any{"tools": map[string]any{}},
+			"serverInfo":      map[string]any{"name": "test", "version": "1.0.0"},
+		}
+	case "tools/list":
+		// two pages to exercise pagination
+		if msg.Params.Cursor == "" {
+			result = map[string]any{
+				"tools": []any{map[string]any{
+					"name":        "echo",
+					"description": "Echoes the text",
+					"inputSchema": map[string]any{
+						"type":       "object",
+						"properties": map[string]any{"text": map[string]any{"type": "string"}},
+						"required":   []string{"text"},
+					},
+				}},
+				"nextCursor": "page2",
+			}
+		} else {
+			result = map[string]any{"tools": []any{map[string]any{"name": "fail", "description": "Always fails"}}}
+		}
+	case "tools/call":
+		var args struct {
+			Text string `json:"text"`
+		}
+		_ = json.Unmarshal(msg.Params.Arguments, &args)
+
+		if msg.Params.Name == "fail" {
+			result = map[string]any{"isError": true, "content": []any{map[string]any{"type": "text", "text": "something broke"}}}
+		} else {
+			result = map[string]any{"content": []any{map[string]any{"type": "text", "text": "echo: " + args.Text}}}
+		}
+	default:
+		return map[string]any{"jsonrpc": "2.0", "id": msg.ID, "error": map[string]any{"code": -32601, "message": "method not found"}}
+	}
+	return map[string]any{"jsonrpc": "2.0", "id": msg.ID, "result": result}
+}
+
+func serveStdio() {
+	scanner := bufio.NewScanner(os.Stdin)
+	enc := json.NewEncoder(os.Stdout)
+	for scanner.Scan() {
+		var msg rpcMessage
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			continue
+		}
+		if resp := handle(msg); resp != nil {
+			_ = enc.Encode(resp)
+		}
+	}
+}
+
+func stdioServer(t *testing.T) mcp.ServerConfig {
+	exe, err := os.Executable()
+	require.NoError(t, err)
+	return mcp.ServerConfig{Name: "stdio", Command: exe, Env: map[string]string{"MCP_TEST_SERVER": "1"}}
+}
+
+func httpServer(t *testing.T, stream bool) mcp.ServerConfig {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			return
+		}
+		require.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+
+		var msg rpcMessage
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&msg))
+
+		resp := handle(msg)
+		if resp == nil {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+
+		w.Header().Set("Mcp-Session-Id", "session-1")
+		if msg.Method != "initialize" {
+			require.Equal(t, "session-1", r.Header.Get("Mcp-Session-Id"))
+		}
+
+		b, _ := json.Marshal(resp)
+		if !stream {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write(b)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = fmt.Fprintf(w, "event: message\ndata: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/progress\"}\n\n")
+		_, _ = fmt.Fprintf(w, "event: message\ndata: %s\n\n", b)
+	}))
+	t.Cleanup(srv.Close)
+
+	t.Setenv("MCP_TEST_TOKEN", "secret")
+	return mcp.ServerConfig{Name: "http", URL: srv.URL, Headers: map[string]string{"Authorization": "Bearer ${MCP_TEST_TOKEN}"}}
+}
+
+func TestRegister_AdaptsServerTools(t *testing.T) {
+	servers := map[string]mcp.ServerConfig{
+		"stdio":       stdioServer(t),
+		"http_json":   httpServer(t, false),
+		"http_stream": httpServer(t, true),
+	}
+
+	for name, cfg := range servers {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			cfg.Prefix = "ext_"
+
+			reg := tools.NewRegistry()
+			clients := mcp.Register(ctx, reg, []mcp.ServerConfig{cfg})
+			require.Len(t, clients, 1)
+			t.Cleanup(func() { _ = clients[0].Close() })
+
+			var names []string
+			for _, tool := range reg.Tools() {
+				names = append(names, tool.Name())
+			}
+			require.Equal(t, []string{"ext_echo", "ext_fail"}, names)
+
+			echo, _ := reg.Get("ext_echo")
+			require.Equal(t, "Echoes the text", echo.Description())
+			require.Equal(t, []any{"text"}, echo.Parameters()["required"])
+
+			out, err := reg.Execute(ctx, "ext_echo", `{"text":"hola"}`)
+			require.NoError(t, err)
+			require.Equal(t, "echo: hola", out)
+
+			_, err = reg.Execute(ctx, "ext_fail", ``)
+			require.EqualError(t, err, "something broke")
+
+			fail, _ := reg.Get("ext_fail")
+			require.Equal(t, "object", fail.Parameters()["type"])
+		})
+	}
+}
+
+func TestRegister_SkipsUnreachableServersAndClashes(t *testing.T) {
+	ctx := context.Background()
+	reg := tools.NewRegistry(tools.NewTodayTool())
+
+	cfg := stdioServer(t)
+	clients := mcp.Register(ctx, reg, []mcp.ServerConfig{
+		{Name: "missing", Command: filepath.Join(t.TempDir(), "does-not-exist")},
+		{Name: "empty"},
+		cfg,
+	})
+	require.Len(t, clients, 1)
+	t.Cleanup(func() { _ = clients[0].Close() })
+
+	_, ok := reg.Get("stdio_echo")
+	require.True(t, ok)
+	require.Len(t, reg.Tools(), 3)
+}
+
+func TestRegister_TimesOutHangingServers(t *testing.T) {
+	cfg := stdioServer(t)
+	cfg.Env["MCP_TEST_SERVER"] = "hang"
+	cfg.ConnectTimeout = "100ms"
+
+	start := time.Now()
+	clients := mcp.Register(context.Background(), tools.NewRegistry(), []mcp.ServerConfig{cfg})
+	require.Empty(t, clients)
+	require.Less(t, time.Since(start), 3*time.Second)
+}
+
+func TestRegister_SkipsInvalidToolNames(t *testing.T) {
+	ctx := context.Background()
+	reg := tools.NewRegistry()
+
+	cfg := stdioServer(t)
+	cfg.Name = "my server"
+	clients := mcp.Register(ctx, reg, []mcp.ServerConfig{cfg})
+	require.Len(t, clients, 1)
+	t.Cleanup(func() { _ = clients[0].Close() })
+	require.Empty(t, reg.Tools())
+
+	cfg.Prefix = strings.Repeat("x", 59) + "_" // 64 characters with the tool name
+	clients = mcp.Register(ctx, reg, []mcp.ServerConfig{cfg})
+	require.Len(t, clients, 1)
+	t.Cleanup(func() { _ = clients[0].Close() })
+
+	var names []string
+	for _, tool := range reg.Tools() {
+		names = append(names, tool.Name())
+	}
+	require.Equal(t, []string{cfg.Prefix + "echo", cfg.Prefix + "fail"}, names)
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mcp.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"mcpServers": {
+		"search": {"url": "http://localhost:9000/mcp", "headers": {"Authorization": "Bearer ${TOKEN}"}},
+		"files": {"command": "mcp-files", "args": ["/data"], "prefix": "files_"}
+	}}`), 0o644))
+
+	servers, err := mcp.LoadConfig(path)
+	require.NoError(t, err)
+	require.Len(t, servers, 2)
+	require.Equal(t, "files", servers[0].Name)
+	require.Equal(t, []string{"/data"}, servers[0].Args)
+	require.Equal(t, "search", servers[1].Name)
+	require.Equal(t, "http://localhost:9000/mcp", servers[1].URL)
+}
@@ -0,0 +1,190 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// stdioTransport talks newline delimited JSON-RPC with a server started as a child process.
+type stdioTransport struct {
+	name  string
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	pending map[string]chan message
+	err     error
+	done    chan struct{}
+}
+
+func newStdioTransport(ctx context.Context, cfg ServerConfig) (*stdioTransport, error) {
+	cmd := exec.CommandContext(ctx, cfg.Command, cfg.Args...)
+	cmd.Env = os.Environ()
+	for k, v := range cfg.Env {
+		cmd.Env = append(cmd.Env, k+"="+os.ExpandEnv(v))
+	}
+	cmd.Stderr = os.Stderr
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	t := &stdioTransport{
+		name:    cfg.Name,
+		cmd:     cmd,
+		stdin:   stdin,
+		pending: map[string]chan message{},
+		done:    make(chan struct{}),
+	}
+	go t.read(stdout)
+	return t, nil
+}
+
+func (t *stdioTransport) read(r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	for scanner.Scan() {
+		var msg message
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			slog.Warn("Ignoring invalid MCP message", "server", t.name, "error", err)
+			continue
+		}
+
+		if msg.Method != "" {
+			t.handleServerRequest(msg)
+			continue
+		}
+
+		t.mu.Lock()
+		ch, ok := t.pending[string(msg.ID)]
+		delete(t.pending, string(msg.ID))
+		t.mu.Unlock()
+		if ok {
+			ch <- msg
+		}
+	}
+
+	err := scanner.Err()
+	if err == nil {
+		err = io.EOF
+	}
+
+	t.mu.Lock()
+	t.err = fmt.Errorf("connection closed: %w", err)
+	t.mu.Unlock()
+	close(t.done)
+}
+
+// handleServerRequest answers pings and rejects any other request the server sends us.
+func (t *stdioTransport) handleServerRequest(msg message) {
+	if len(msg.ID) == 0 {
+		return // notification
+	}
+
+	resp := map[string]any{"jsonrpc": "2.0", "id": msg.ID}
+	if msg.Method == "ping" {
+		resp["result"] = map[string]any{}
+	} else {
+		resp["error"] = rpcError{Code: -32601, Message: "method not found"}
+	}
+	if err := t.write(resp); err != nil {
+		slog.Warn("Failed to answer MCP server request", "server", t.name, "method", msg.Method, "error", err)
+	}
+}
+
+func (t *stdioTransport) call(ctx context.Context, req request) (message, error) {
+	key := strconv.FormatInt(*req.ID, 10)
+	ch := make(chan message, 1)
+
+	t.mu.Lock()
+	if t.err != nil {
+		err := t.err
+		t.mu.Unlock()
+		return message{}, err
+	}
+	t.pending[key] = ch
+	t.mu.Unlock()
+
+	if err := t.write(req); err != nil {
+		t.forget(key)
+		return message{}, err
+	}
+
+	select {
+	case msg := <-ch:
+		return msg, nil
+	case <-t.done:
+		t.forget(key)
+		return message{}, t.closedErr()
+	case <-ctx.Done():
+		t.forget(key)
+		_ = t.write(request{JSONRPC: "2.0", Method: "notifications/cancelled", Params: map[string]any{"requestId": *req.ID}})
+		return message{}, ctx.Err()
+	}
+}
+
+func (t *stdioTransport) notify(_ context.Context, req request) error {
+	return t.write(req)
+}
+
+func (t *stdioTransport) write(v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+	_, err = t.stdin.Write(append(b, '\n'))
+	return err
+}
+
+func (t *stdioTransport) forget(key string) {
+	t.mu.Lock()
+	delete(t.pending, key)
+	t.mu.Unlock()
+}
+
+func (t *stdioTransport) closedErr() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.err
+}
+
+// Close closes the server stdin, which asks it to exit, and kills it if it is still running after 5s.
+func (t *stdioTransport) Close() error {
+	_ = t.stdin.Close()
+
+	exited := make(chan struct{})
+	go func() {
+		_ = t.cmd.Wait()
+		close(exited)
+	}()
+
+	select {
+	case <-exited:
+		return nil
+	case <-time.After(5 * time.Second):
+		return t.cmd.Process.Kill()
+	}
+}
@@ -0,0 +1,106 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"regexp"
+
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/chat/assistant/tools"
+	"github.com/openai/openai-go/v2"
+)
+
+// Tool adapts a tool of an MCP server to tools.Tool.
+type Tool struct {
+	client *Client
+	name   string
+	info   ToolInfo
+}
+
+func (t *Tool) Name() string        { return t.name }
+func (t *Tool) Description() string { return t.info.Description }
+
+func (t *Tool) Parameters() openai.FunctionParameters {
+	if len(t.info.InputSchema) == 0 {
+		return openai.FunctionParameters{"type": "object", "properties": map[string]any{}}
+	}
+	return openai.FunctionParameters(t.info.InputSchema)
+}
+
+func (t *Tool) Call(ctx context.Context, rawArgs string) (string, error) {
+	return t.client.CallTool(ctx, t.info.Name, json.RawMessage(rawArgs))
+}
+
+// toolName is the pattern OpenAI accepts for function names.
+var toolName = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+// Tools lists the tools of the server, named with prefix. Tools whose prefixed name is not a valid
+// function name are logged and left out.
+func (c *Client) Tools(ctx context.Context, prefix string) ([]tools.Tool, error) {
+	infos, err := c.ListTools(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]tools.Tool, 0, len(infos))
+	for _, info := range infos {
+		name := prefix + info.Name
+		if !toolName.MatchString(name) {
+			slog.WarnContext(ctx, "Skipping MCP tool with an invalid name", "server", c.name, "tool", name)
+			continue
+		}
+		out = append(out, &Tool{client: c, name: name, info: info})
+	}
+	return out, nil
+}
+
+// Register connects to every server and registers its tools in reg, named after the server. Servers
+// that cannot be reached or do not answer within their connect timeout are logged and skipped so that
+// they do not prevent the assistant from starting. The returned clients must be closed on shutdown.
+func Register(ctx context.Context, reg *tools.Registry, servers []ServerConfig) []*Client {
+	var clients []*Client
+	for _, cfg := range servers {
+		c, err := Connect(ctx, cfg)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to connect to MCP server", "server", cfg.Name, "error", err)
+			continue
+		}
+
+		listCtx, cancel := context.WithTimeout(ctx, cfg.connectTimeout())
+		ts, err := c.Tools(listCtx, cfg.prefix())
+		cancel()
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to list MCP server tools", "server", cfg.Name, "error", err)
+			_ = c.Close()
+			continue
+		}
+
+		for _, t := range ts {
+			if _, ok := reg.Get(t.Name()); ok {
+				slog.WarnContext(ctx, "Skipping MCP tool that clashes with an existing tool", "server", cfg.Name, "tool", t.Name())
+				continue
+			}
+			reg.Register(t)
+		}
+
+		slog.InfoContext(ctx, "Registered MCP server tools", "server", cfg.Name, "tools", len(ts))
+		clients = append(clients, c)
+	}
+	return clients
+}
+
+// RegisterFromEnv registers the servers configured in the file at MCP_CONFIG, if set.
+func RegisterFromEnv(ctx context.Context, reg *tools.Registry) []*Client {
+	path := os.Getenv("MCP_CONFIG")
+	if path == "" {
+		return nil
+	}
+
+	servers, err := LoadConfig(path)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to load MCP config", "path", path, "error", err)
+		return nil
+	}
+	return Register(ctx, reg, servers)
+}
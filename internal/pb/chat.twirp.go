@@ -32,18 +32,24 @@ const _ = twirp.TwirpPackageMinVersion_8_1_0
 // =====================
 
 type ChatService interface {
-	// create a new conversation by sending a message and getting a reply
+	// Create a new conversation by sending a message and getting a reply
 	// use ContinueConversation with the returned conversation_id to continue the conversation
 	StartConversation(context.Context, *StartConversationRequest) (*StartConversationResponse, error)
 
-	// continue an existing conversation by adding a new message and getting a reply
+	// Continue an existing conversation by adding a new message and getting a reply
 	ContinueConversation(context.Context, *ContinueConversationRequest) (*ContinueConversationResponse, error)
 
-	// list most recent conversations
+	// List most recent conversations
 	ListConversations(context.Context, *ListConversationsRequest) (*ListConversationsResponse, error)
 
-	// describe a conversation by its ID
+	// Describe a conversation by its ID
 	DescribeConversation(context.Context, *DescribeConversationRequest) (*DescribeConversationResponse, error)
+
+	// Summarize a conversation into a short TL;DR, its key decisions and action items
+	SummarizeConversation(context.Context, *SummarizeConversationRequest) (*SummarizeConversationResponse, error)
+
+	// Approve or reject the action the assistant is waiting on and resume the reply
+	ResolvePendingAction(context.Context, *ResolvePendingActionRequest) (*ResolvePendingActionResponse, error)
 }
 
 // ===========================
@@ -52,7 +58,7 @@ type ChatService interface {
 
 type chatServiceProtobufClient struct {
 	client      HTTPClient
-	urls        [4]string
+	urls        [6]string
 	interceptor twirp.Interceptor
 	opts        twirp.ClientOptions
 }
@@ -80,11 +86,13 @@ func NewChatServiceProtobufClient(baseURL string, client HTTPClient, opts ...twi
 	// Build method URLs: <baseURL>[<prefix>]/<package>.<Service>/<Method>
 	serviceURL := sanitizeBaseURL(baseURL)
 	serviceURL += baseServicePath(pathPrefix, "acai.chat", "ChatService")
-	urls := [4]string{
+	urls := [6]string{
 		serviceURL + "StartConversation",
 		serviceURL + "ContinueConversation",
 		serviceURL + "ListConversations",
 		serviceURL + "DescribeConversation",
+		serviceURL + "SummarizeConversation",
+		serviceURL + "ResolvePendingAction",
 	}
 
 	return &chatServiceProtobufClient{
@@ -279,13 +287,105 @@ func (c *chatServiceProtobufClient) callDescribeConversation(ctx context.Context
 	return out, nil
 }
 
+func (c *chatServiceProtobufClient) SummarizeConversation(ctx context.Context, in *SummarizeConversationRequest) (*SummarizeConversationResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "SummarizeConversation")
+	caller := c.callSummarizeConversation
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *SummarizeConversationRequest) (*SummarizeConversationResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*SummarizeConversationRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*SummarizeConversationRequest) when calling interceptor")
+					}
+					return c.callSummarizeConversation(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*SummarizeConversationResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*SummarizeConversationResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceProtobufClient) callSummarizeConversation(ctx context.Context, in *SummarizeConversationRequest) (*SummarizeConversationResponse, error) {
+	out := new(SummarizeConversationResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[4], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *chatServiceProtobufClient) ResolvePendingAction(ctx context.Context, in *ResolvePendingActionRequest) (*ResolvePendingActionResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "ResolvePendingAction")
+	caller := c.callResolvePendingAction
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *ResolvePendingActionRequest) (*ResolvePendingActionResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ResolvePendingActionRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ResolvePendingActionRequest) when calling interceptor")
+					}
+					return c.callResolvePendingAction(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ResolvePendingActionResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ResolvePendingActionResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceProtobufClient) callResolvePendingAction(ctx context.Context, in *ResolvePendingActionRequest) (*ResolvePendingActionResponse, error) {
+	out := new(ResolvePendingActionResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[5], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
 // =======================
 // ChatService JSON Client
 // =======================
 
 type chatServiceJSONClient struct {
 	client      HTTPClient
-	urls        [4]string
+	urls        [6]string
 	interceptor twirp.Interceptor
 	opts        twirp.ClientOptions
 }
@@ -313,11 +413,13 @@ func NewChatServiceJSONClient(baseURL string, client HTTPClient, opts ...twirp.C
 	// Build method URLs: <baseURL>[<prefix>]/<package>.<Service>/<Method>
 	serviceURL := sanitizeBaseURL(baseURL)
 	serviceURL += baseServicePath(pathPrefix, "acai.chat", "ChatService")
-	urls := [4]string{
+	urls := [6]string{
 		serviceURL + "StartConversation",
 		serviceURL + "ContinueConversation",
 		serviceURL + "ListConversations",
 		serviceURL + "DescribeConversation",
+		serviceURL + "SummarizeConversation",
+		serviceURL + "ResolvePendingAction",
 	}
 
 	return &chatServiceJSONClient{
@@ -512,6 +614,98 @@ func (c *chatServiceJSONClient) callDescribeConversation(ctx context.Context, in
 	return out, nil
 }
 
+func (c *chatServiceJSONClient) SummarizeConversation(ctx context.Context, in *SummarizeConversationRequest) (*SummarizeConversationResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "SummarizeConversation")
+	caller := c.callSummarizeConversation
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *SummarizeConversationRequest) (*SummarizeConversationResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*SummarizeConversationRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*SummarizeConversationRequest) when calling interceptor")
+					}
+					return c.callSummarizeConversation(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*SummarizeConversationResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*SummarizeConversationResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceJSONClient) callSummarizeConversation(ctx context.Context, in *SummarizeConversationRequest) (*SummarizeConversationResponse, error) {
+	out := new(SummarizeConversationResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[4], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *chatServiceJSONClient) ResolvePendingAction(ctx context.Context, in *ResolvePendingActionRequest) (*ResolvePendingActionResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "ResolvePendingAction")
+	caller := c.callResolvePendingAction
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *ResolvePendingActionRequest) (*ResolvePendingActionResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ResolvePendingActionRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ResolvePendingActionRequest) when calling interceptor")
+					}
+					return c.callResolvePendingAction(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ResolvePendingActionResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ResolvePendingActionResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceJSONClient) callResolvePendingAction(ctx context.Context, in *ResolvePendingActionRequest) (*ResolvePendingActionResponse, error) {
+	out := new(ResolvePendingActionResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[5], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
 // ==========================
 // ChatService Server Handler
 // ==========================
@@ -621,6 +815,12 @@ func (s *chatServiceServer) ServeHTTP(resp http.ResponseWriter, req *http.Reques
 	case "DescribeConversation":
 		s.serveDescribeConversation(ctx, resp, req)
 		return
+	case "SummarizeConversation":
+		s.serveSummarizeConversation(ctx, resp, req)
+		return
+	case "ResolvePendingAction":
+		s.serveResolvePendingAction(ctx, resp, req)
+		return
 	default:
 		msg := fmt.Sprintf("no handler for path %q", req.URL.Path)
 		s.writeError(ctx, resp, badRouteError(msg, req.Method, req.URL.Path))
@@ -1348,6 +1548,366 @@ func (s *chatServiceServer) serveDescribeConversationProtobuf(ctx context.Contex
 	callResponseSent(ctx, s.hooks)
 }
 
+func (s *chatServiceServer) serveSummarizeConversation(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveSummarizeConversationJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveSummarizeConversationProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *chatServiceServer) serveSummarizeConversationJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "SummarizeConversation")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(SummarizeConversationRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.ChatService.SummarizeConversation
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *SummarizeConversationRequest) (*SummarizeConversationResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*SummarizeConversationRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*SummarizeConversationRequest) when calling interceptor")
+					}
+					return s.ChatService.SummarizeConversation(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*SummarizeConversationResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*SummarizeConversationResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *SummarizeConversationResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *SummarizeConversationResponse and nil error while calling SummarizeConversation. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveSummarizeConversationProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "SummarizeConversation")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(SummarizeConversationRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.ChatService.SummarizeConversation
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *SummarizeConversationRequest) (*SummarizeConversationResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*SummarizeConversationRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*SummarizeConversationRequest) when calling interceptor")
+					}
+					return s.ChatService.SummarizeConversation(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*SummarizeConversationResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*SummarizeConversationResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *SummarizeConversationResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *SummarizeConversationResponse and nil error while calling SummarizeConversation. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveResolvePendingAction(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveResolvePendingActionJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveResolvePendingActionProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *chatServiceServer) serveResolvePendingActionJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ResolvePendingAction")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(ResolvePendingActionRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.ChatService.ResolvePendingAction
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *ResolvePendingActionRequest) (*ResolvePendingActionResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ResolvePendingActionRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ResolvePendingActionRequest) when calling interceptor")
+					}
+					return s.ChatService.ResolvePendingAction(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ResolvePendingActionResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ResolvePendingActionResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *ResolvePendingActionResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ResolvePendingActionResponse and nil error while calling ResolvePendingAction. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveResolvePendingActionProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ResolvePendingAction")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(ResolvePendingActionRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.ChatService.ResolvePendingAction
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *ResolvePendingActionRequest) (*ResolvePendingActionResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ResolvePendingActionRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ResolvePendingActionRequest) when calling interceptor")
+					}
+					return s.ChatService.ResolvePendingAction(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ResolvePendingActionResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ResolvePendingActionResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *ResolvePendingActionResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ResolvePendingActionResponse and nil error while calling ResolvePendingAction. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
 func (s *chatServiceServer) ServiceDescriptor() ([]byte, int) {
 	return twirpFileDescriptor0, 0
 }
@@ -1929,39 +2489,61 @@ func callClientError(ctx context.Context, h *twirp.ClientHooks, err twirp.Error)
 }
 
 var twirpFileDescriptor0 = []byte{
-	// 529 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x94, 0x51, 0x6b, 0xd3, 0x50,
-	0x14, 0xc7, 0x4d, 0xda, 0xd9, 0xe5, 0x64, 0xad, 0xdd, 0xa5, 0x60, 0x96, 0x15, 0x56, 0xa2, 0xb8,
-	0x3e, 0x48, 0x2a, 0x75, 0x0f, 0xc2, 0xf0, 0x61, 0x56, 0x85, 0xa1, 0x56, 0x48, 0x3a, 0x84, 0x09,
-	0xd3, 0x34, 0xbb, 0x66, 0x17, 0xd2, 0xdc, 0x98, 0x7b, 0x3b, 0xf0, 0xd3, 0xec, 0x83, 0xfa, 0x22,
-	0x4d, 0x6e, 0xe3, 0xbd, 0x34, 0xa9, 0x8a, 0x8f, 0xe7, 0xe4, 0x7f, 0xcf, 0xf9, 0xfd, 0xcf, 0x39,
-	0x04, 0x3a, 0x59, 0x1a, 0x8e, 0xc2, 0x9b, 0x80, 0xbb, 0x69, 0x46, 0x39, 0x45, 0x46, 0x10, 0x06,
-	0xc4, 0x5d, 0x25, 0xec, 0xa3, 0x88, 0xd2, 0x28, 0xc6, 0xa3, 0xfc, 0xc3, 0x7c, 0xf9, 0x6d, 0xc4,
-	0xc9, 0x02, 0x33, 0x1e, 0x2c, 0xd2, 0x42, 0xeb, 0xfc, 0xd4, 0x61, 0x6f, 0x42, 0x93, 0x5b, 0x9c,
-	0xb1, 0x80, 0x13, 0x9a, 0xa0, 0x0e, 0xe8, 0xe4, 0xda, 0xd2, 0x06, 0xda, 0xd0, 0xf0, 0x74, 0x72,
-	0x8d, 0x7a, 0xb0, 0xc3, 0x09, 0x8f, 0xb1, 0xa5, 0xe7, 0xa9, 0x22, 0x40, 0x2f, 0xc0, 0x28, 0x2b,
-	0x59, 0x8d, 0x81, 0x36, 0x34, 0xc7, 0xb6, 0x5b, 0xf4, 0x72, 0xd7, 0xbd, 0xdc, 0xd9, 0x5a, 0xe1,
-	0xfd, 0x16, 0xa3, 0x53, 0xd8, 0x5d, 0x60, 0xc6, 0x82, 0x08, 0x33, 0xab, 0x39, 0x68, 0x0c, 0xcd,
-	0xf1, 0x91, 0x5b, 0xf2, 0xba, 0x32, 0x8a, 0xfb, 0xa1, 0xd0, 0x79, 0xe5, 0x03, 0xfb, 0x4e, 0x83,
-	0x96, 0xc8, 0x6e, 0x80, 0x3e, 0x83, 0x66, 0x46, 0x05, 0x67, 0x67, 0xdc, 0xaf, 0x2b, 0xea, 0xd1,
-	0x18, 0x7b, 0xb9, 0x12, 0x59, 0xd0, 0x0a, 0x69, 0xc2, 0x71, 0xc2, 0x73, 0x0b, 0x86, 0xb7, 0x0e,
-	0x55, 0x7b, 0xcd, 0x7f, 0xb0, 0xe7, 0x3c, 0x85, 0xe6, 0xaa, 0x03, 0x32, 0xa1, 0x75, 0x31, 0x7d,
-	0x37, 0xfd, 0xf8, 0x69, 0xda, 0xbd, 0x87, 0x76, 0xa1, 0x79, 0xe1, 0xbf, 0xf1, 0xba, 0x1a, 0x6a,
-	0x83, 0x71, 0xe6, 0xfb, 0xe7, 0xfe, 0xec, 0x6c, 0x3a, 0xeb, 0xea, 0xce, 0x09, 0x58, 0x3e, 0x0f,
-	0x32, 0x2e, 0x13, 0x7a, 0xf8, 0xfb, 0x12, 0x33, 0xbe, 0xa2, 0x13, 0xbe, 0x85, 0xc9, 0x75, 0xe8,
-	0xa4, 0x70, 0x50, 0xf1, 0x8a, 0xa5, 0x34, 0x61, 0x18, 0x1d, 0xc3, 0x83, 0x50, 0xca, 0x7f, 0x29,
-	0x67, 0xd4, 0x91, 0xd3, 0xe7, 0x75, 0x8b, 0xed, 0xc1, 0x4e, 0x86, 0xd3, 0xf8, 0x87, 0x98, 0x48,
-	0x11, 0x38, 0x5f, 0xe1, 0x70, 0x42, 0x13, 0x4e, 0x92, 0x25, 0xae, 0x42, 0xfd, 0xeb, 0x9e, 0x92,
-	0x27, 0x5d, 0xf5, 0x74, 0x02, 0xfd, 0xea, 0x0e, 0xc2, 0x56, 0xc9, 0xa5, 0xc9, 0x5c, 0x36, 0x58,
-	0xef, 0x09, 0x53, 0x06, 0xc1, 0x04, 0x94, 0x73, 0x09, 0x07, 0x15, 0xdf, 0x44, 0xb9, 0x97, 0xd0,
-	0x96, 0xd1, 0x98, 0xa5, 0xe5, 0xa7, 0xf8, 0xb0, 0xe6, 0x6a, 0x3c, 0x55, 0xed, 0xbc, 0x85, 0xc3,
-	0xd7, 0x98, 0x85, 0x19, 0x99, 0xff, 0xd7, 0x3c, 0x9c, 0xcf, 0xd0, 0xaf, 0xae, 0x23, 0x30, 0x4f,
-	0x61, 0x4f, 0x7e, 0x91, 0x57, 0xd9, 0x42, 0xa9, 0x88, 0xc7, 0x77, 0x0d, 0x30, 0x27, 0x37, 0x01,
-	0xf7, 0x71, 0x76, 0x4b, 0x42, 0x8c, 0xae, 0x60, 0x7f, 0xe3, 0x6c, 0xd0, 0x23, 0xa9, 0x56, 0xdd,
-	0x29, 0xda, 0x8f, 0xb7, 0x8b, 0x04, 0x6c, 0x04, 0xbd, 0xaa, 0x15, 0xa2, 0x27, 0x2a, 0x6e, 0xdd,
-	0x15, 0xd9, 0xc7, 0x7f, 0xd4, 0x89, 0x46, 0x57, 0xb0, 0xbf, 0xb1, 0x59, 0xc5, 0x48, 0xdd, 0x4d,
-	0x28, 0x46, 0xea, 0x8f, 0x23, 0x82, 0x5e, 0xd5, 0x56, 0x14, 0x23, 0x5b, 0xd6, 0xaf, 0x18, 0xd9,
-	0xb6, 0xde, 0x57, 0xed, 0x4b, 0x93, 0x24, 0x1c, 0x67, 0x49, 0x10, 0x8f, 0xd2, 0xf9, 0xfc, 0x7e,
-	0xfe, 0x6b, 0x79, 0xfe, 0x2b, 0x00, 0x00, 0xff, 0xff, 0x59, 0x83, 0x17, 0xf4, 0xd0, 0x05, 0x00,
-	0x00,
+	// 888 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xc4, 0x56, 0x6f, 0x6f, 0xdb, 0x44,
+	0x18, 0xc7, 0x89, 0xdb, 0xc6, 0x4f, 0x9a, 0xd0, 0x9d, 0xca, 0xf0, 0xdc, 0xa0, 0x65, 0xde, 0x44,
+	0xf3, 0x02, 0xb9, 0xa8, 0xbc, 0x99, 0x34, 0x10, 0x94, 0x0e, 0x50, 0x05, 0x14, 0xe4, 0x74, 0x9a,
+	0x34, 0xa4, 0x45, 0x57, 0xe7, 0xe6, 0x1d, 0x73, 0xee, 0x8c, 0xef, 0x52, 0xa9, 0x93, 0x78, 0xc1,
+	0xa7, 0x00, 0xf1, 0x6d, 0xf8, 0x0c, 0xbc, 0xe2, 0x7b, 0xf0, 0x01, 0x90, 0xed, 0x73, 0x72, 0x5e,
+	0x6c, 0x37, 0xa5, 0x2f, 0xf6, 0x2e, 0xcf, 0xe3, 0xdf, 0x3d, 0x7f, 0x7e, 0xf7, 0xfb, 0x9d, 0x02,
+	0xfd, 0x24, 0x0e, 0x0e, 0x82, 0x97, 0x58, 0x7a, 0x71, 0xc2, 0x25, 0x47, 0x16, 0x0e, 0x30, 0xf5,
+	0xd2, 0x84, 0x73, 0x37, 0xe4, 0x3c, 0x8c, 0xc8, 0x41, 0xf6, 0xe1, 0x7c, 0xfe, 0xe2, 0x40, 0xd2,
+	0x19, 0x11, 0x12, 0xcf, 0xe2, 0x1c, 0xeb, 0xfe, 0x61, 0xc2, 0xf6, 0x31, 0x67, 0x17, 0x24, 0x11,
+	0x58, 0x52, 0xce, 0x50, 0x1f, 0x5a, 0x74, 0x6a, 0x1b, 0x43, 0x63, 0x64, 0xf9, 0x2d, 0x3a, 0x45,
+	0xbb, 0xb0, 0x21, 0xa9, 0x8c, 0x88, 0xdd, 0xca, 0x52, 0x79, 0x80, 0x1e, 0x82, 0xb5, 0xa8, 0x64,
+	0xb7, 0x87, 0xc6, 0xa8, 0x7b, 0xe8, 0x78, 0x79, 0x2f, 0xaf, 0xe8, 0xe5, 0x9d, 0x15, 0x08, 0x7f,
+	0x09, 0x46, 0x8f, 0xa0, 0x33, 0x23, 0x42, 0xe0, 0x90, 0x08, 0xdb, 0x1c, 0xb6, 0x47, 0xdd, 0xc3,
+	0xbb, 0xde, 0x62, 0x5e, 0x4f, 0x1f, 0xc5, 0xfb, 0x3e, 0xc7, 0xf9, 0x8b, 0x03, 0xe8, 0x73, 0xe8,
+	0xc7, 0x84, 0x4d, 0x29, 0x0b, 0x27, 0x38, 0x48, 0x31, 0xf6, 0x46, 0xd6, 0xdb, 0xd6, 0x4a, 0xfc,
+	0x98, 0x03, 0x8e, 0xb2, 0xef, 0x7e, 0x2f, 0xd6, 0x43, 0xf4, 0x10, 0xba, 0x71, 0x42, 0x5e, 0x90,
+	0x84, 0xb0, 0x80, 0x08, 0x7b, 0x33, 0x3b, 0x7d, 0x5b, 0x3f, 0xbd, 0xfc, 0xea, 0xeb, 0x50, 0xe7,
+	0x2f, 0x03, 0xb6, 0xd4, 0x40, 0x2b, 0x1c, 0x7d, 0x0c, 0x66, 0xc2, 0x15, 0x45, 0xfd, 0xc3, 0x41,
+	0xdd, 0x3e, 0x3e, 0x8f, 0x88, 0x9f, 0x21, 0x91, 0x0d, 0x5b, 0x01, 0x67, 0x92, 0x30, 0x99, 0xb1,
+	0x67, 0xf9, 0x45, 0x58, 0x66, 0xd6, 0xbc, 0x0e, 0xb3, 0x43, 0xe8, 0x8a, 0x79, 0x18, 0x12, 0x91,
+	0x36, 0x13, 0xf6, 0xc6, 0xb0, 0x3d, 0xb2, 0x7c, 0x3d, 0xe5, 0x7e, 0x04, 0x66, 0x3a, 0x03, 0xea,
+	0xc2, 0xd6, 0x93, 0xd3, 0x6f, 0x4f, 0x7f, 0x78, 0x7a, 0xba, 0xf3, 0x0e, 0xea, 0x80, 0xf9, 0x64,
+	0xfc, 0x95, 0xbf, 0x63, 0xa0, 0x1e, 0x58, 0x47, 0xe3, 0xf1, 0xc9, 0xf8, 0xec, 0xe8, 0xf4, 0x6c,
+	0xa7, 0xe5, 0x3e, 0x85, 0xae, 0xc6, 0x46, 0x2a, 0x84, 0x39, 0xa3, 0x52, 0xa8, 0xbd, 0xf3, 0x00,
+	0xdd, 0x86, 0xcd, 0x88, 0x07, 0x78, 0xa1, 0x0f, 0x15, 0x21, 0x07, 0x3a, 0xe9, 0x64, 0xaf, 0x39,
+	0x23, 0x6a, 0xc3, 0x45, 0xec, 0xfe, 0x63, 0x40, 0xaf, 0x74, 0x4b, 0x2b, 0x84, 0x7e, 0x01, 0x20,
+	0x39, 0x8f, 0x26, 0x01, 0x8e, 0x22, 0x61, 0xb7, 0x32, 0x99, 0xdc, 0xab, 0xbb, 0x63, 0xef, 0x8c,
+	0xf3, 0xe8, 0x18, 0x47, 0x91, 0x6f, 0x49, 0xf5, 0x4b, 0xfc, 0x7f, 0x81, 0x3a, 0x9f, 0x42, 0xa7,
+	0x28, 0x88, 0x10, 0x98, 0x69, 0x49, 0x35, 0x59, 0xf6, 0x1b, 0x0d, 0xc0, 0xc2, 0x49, 0x38, 0x9f,
+	0x11, 0x26, 0x85, 0x5a, 0x7a, 0x99, 0x70, 0x19, 0xd8, 0x63, 0x89, 0x13, 0xa9, 0x5f, 0xbc, 0x4f,
+	0x7e, 0x99, 0x13, 0x21, 0xd3, 0x4b, 0x57, 0x4a, 0x56, 0x05, 0x8b, 0xf0, 0x4d, 0x59, 0xb6, 0xd6,
+	0x96, 0xa5, 0xfb, 0xb7, 0x01, 0x77, 0x2a, 0x1a, 0x8a, 0x98, 0x33, 0x41, 0xd0, 0x3e, 0xbc, 0x1b,
+	0x68, 0xf9, 0xc9, 0x82, 0xe4, 0xbe, 0x9e, 0x3e, 0xa9, 0x73, 0xf9, 0x2e, 0x6c, 0x24, 0x24, 0x8e,
+	0x2e, 0xd5, 0x0d, 0xe6, 0xc1, 0x9b, 0x3a, 0x33, 0x57, 0x74, 0x76, 0x63, 0x9b, 0xba, 0x7f, 0x1a,
+	0xb0, 0x77, 0xcc, 0x99, 0xa4, 0x6c, 0x4e, 0xaa, 0x98, 0x5c, 0x7b, 0x2f, 0x8d, 0xf2, 0x56, 0x23,
+	0xe5, 0xed, 0xf5, 0x29, 0xff, 0xdd, 0x80, 0x41, 0xf5, 0x70, 0x8a, 0xf5, 0x05, 0x6d, 0x46, 0x03,
+	0x6d, 0xad, 0x75, 0x68, 0x6b, 0x5f, 0x8f, 0x36, 0x07, 0xec, 0xef, 0xa8, 0x28, 0x49, 0x41, 0x28,
+	0xca, 0xdc, 0x67, 0x70, 0xa7, 0xe2, 0x9b, 0x9a, 0xf8, 0x33, 0xe8, 0xe9, 0xc4, 0xa5, 0x1e, 0x4f,
+	0x2d, 0xf7, 0x7e, 0xcd, 0x4b, 0xe6, 0x97, 0xd1, 0xee, 0xd7, 0xb0, 0xf7, 0x98, 0x88, 0x20, 0xa1,
+	0xe7, 0x37, 0xba, 0x2d, 0xf7, 0x27, 0x18, 0x54, 0xd7, 0x51, 0x63, 0x3e, 0x82, 0x6d, 0xfd, 0x44,
+	0x56, 0xa5, 0x61, 0xca, 0x12, 0xd8, 0xfd, 0x06, 0x06, 0xe3, 0xf9, 0x6c, 0x86, 0x13, 0xfa, 0xfa,
+	0x66, 0x53, 0xfe, 0x66, 0xc0, 0x07, 0x35, 0x95, 0xd4, 0x9c, 0x36, 0x6c, 0x89, 0x0c, 0x50, 0x48,
+	0xa0, 0x08, 0xd1, 0x7d, 0xe8, 0xbd, 0x22, 0x97, 0x93, 0x29, 0x09, 0xa8, 0xd0, 0x64, 0xb0, 0xfd,
+	0x8a, 0x5c, 0x3e, 0x2e, 0x72, 0xe8, 0x1e, 0x6c, 0xe7, 0xf7, 0x3f, 0xa1, 0x92, 0xcc, 0x52, 0x6d,
+	0x66, 0x52, 0xc9, 0x73, 0x27, 0x69, 0xca, 0xfd, 0x15, 0xf6, 0x7c, 0x22, 0x78, 0x74, 0x41, 0xca,
+	0x82, 0xb8, 0xae, 0x3f, 0xf6, 0xc0, 0x2a, 0x5a, 0x4d, 0x95, 0x43, 0x3a, 0x38, 0x58, 0x9a, 0x07,
+	0xc7, 0x71, 0xc2, 0x2f, 0xf2, 0x27, 0xbc, 0xe3, 0x17, 0x61, 0x66, 0x81, 0xea, 0xfe, 0x6f, 0xd9,
+	0x02, 0x87, 0xff, 0x9a, 0xd0, 0x3d, 0x7e, 0x89, 0xe5, 0x98, 0x24, 0x17, 0x34, 0x20, 0xe8, 0x39,
+	0xdc, 0x5a, 0x79, 0x1e, 0xd1, 0x7d, 0xad, 0x5a, 0xdd, 0x6b, 0xed, 0x3c, 0x68, 0x06, 0xa9, 0x45,
+	0x43, 0xd8, 0xad, 0x7a, 0x0b, 0xd0, 0x87, 0x65, 0x51, 0xd6, 0xbd, 0x64, 0xce, 0xfe, 0x95, 0x38,
+	0xd5, 0xe8, 0x39, 0xdc, 0x5a, 0xf1, 0x6f, 0x69, 0x91, 0x3a, 0xe7, 0x3b, 0x0f, 0x9a, 0x41, 0xcb,
+	0x45, 0xaa, 0xbc, 0x57, 0x5a, 0xa4, 0xc1, 0xe4, 0xce, 0xfe, 0x95, 0x38, 0xd5, 0xe8, 0x67, 0x78,
+	0xaf, 0xd2, 0x3d, 0x48, 0xaf, 0xd0, 0xe4, 0x54, 0x67, 0x74, 0x35, 0x70, 0xb9, 0x54, 0x95, 0x4c,
+	0x4b, 0x4b, 0x35, 0xf8, 0xc8, 0xd9, 0xbf, 0x12, 0x97, 0x37, 0xfa, 0xb2, 0xf7, 0xac, 0x4b, 0x99,
+	0x24, 0x09, 0xc3, 0xd1, 0x41, 0x7c, 0x7e, 0xbe, 0x99, 0xfd, 0xc5, 0xf8, 0xe4, 0xbf, 0x01, 0x00,
+	0xd5, 0x09, 0x80, 0x7c, 0x9a, 0x0b, 0x00, 0x00,
 }
@@ -0,0 +1,261 @@
+package weather
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	DefaultCurrentTTL  = 10 * time.Minute
+	DefaultForecastTTL = time.Hour
+	// settledTTL is the TTL of data that does not change anymore: observed days, climate and places.
+	settledTTL = 24 * time.Hour
+
+	cacheSize = 1000
+)
+
+// Cache keeps the answers of a provider, keyed by the normalized location, the endpoint and its
+// parameters. Identical requests in flight are coalesced into a single call.
+type Cache struct {
+	provider    Provider
+	currentTTL  time.Duration
+	forecastTTL time.Duration
+	// horizon is the number of days forecast by the provider, 0 when unknown.
+	horizon int
+	now     func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	group   singleflight.Group
+	metrics *cacheMetrics
+}
+
+type cacheEntry struct {
+	value     any
+	expiresAt time.Time
+}
+
+// NewCache caches current conditions for currentTTL and forecasts for forecastTTL, DefaultCurrentTTL and
+// DefaultForecastTTL when not positive. Dates past the forecast horizon of provider are kept longer.
+func NewCache(provider Provider, currentTTL, forecastTTL time.Duration) *Cache {
+	if currentTTL <= 0 {
+		currentTTL = DefaultCurrentTTL
+	}
+	if forecastTTL <= 0 {
+		forecastTTL = DefaultForecastTTL
+	}
+	horizon := 0
+	if f, ok := provider.(forecaster); ok {
+		horizon = f.forecastDays()
+	}
+	return &Cache{
+		provider:    provider,
+		horizon:     horizon,
+		currentTTL:  currentTTL,
+		forecastTTL: forecastTTL,
+		now:         time.Now,
+		entries:     make(map[string]cacheEntry),
+		metrics:     sharedCacheMetrics(),
+	}
+}
+
+func (c *Cache) Name() string { return c.provider.Name() }
+
+func (c *Cache) Fetch(ctx context.Context, q Query) (Result, error) {
+	endpoint, ttl := c.endpoint(q)
+	key := strings.Join([]string{
+		endpoint,
+		normalizeLocation(q),
+		"days=" + strconv.Itoa(clampDays(q.Days)),
+		"hours=" + strconv.Itoa(clampHours(q.Hours)),
+		"alerts=" + strconv.FormatBool(q.Alerts),
+		"aqi=" + strconv.FormatBool(q.AirQuality),
+		"date=" + dateKey(q.Date),
+		"end=" + dateKey(q.EndDate),
+	}, "|")
+
+	v, err := c.get(ctx, endpoint, key, ttl, func(ctx context.Context) (any, error) {
+		return c.provider.Fetch(ctx, q)
+	})
+	if err != nil {
+		return Result{}, err
+	}
+	return v.(Result), nil
+}
+
+// Search caches the places found by the provider, if it is a Geocoder.
+func (c *Cache) Search(ctx context.Context, name string, limit int) ([]Geocode, error) {
+	g, ok := c.provider.(Geocoder)
+	if !ok {
+		return nil, ErrNoGeocoder
+	}
+
+	key := "search|" + strings.ToLower(strings.Join(strings.Fields(name), " ")) + "|" + strconv.Itoa(limit)
+	v, err := c.get(ctx, "search", key, settledTTL, func(ctx context.Context) (any, error) {
+		return g.Search(ctx, name, limit)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.([]Geocode), nil
+}
+
+// endpoint names the kind of request and its TTL. Days far enough ahead are answered with long range
+// forecasts or climate data that barely change.
+func (c *Cache) endpoint(q Query) (string, time.Duration) {
+	switch {
+	case !q.Date.IsZero() && daysFromToday(q.lastDate()) < 0:
+		return "history", settledTTL
+	case !q.Date.IsZero() && c.horizon > 0 && daysFromToday(q.Date) >= c.horizon:
+		return "future", settledTTL
+	case !q.Date.IsZero():
+		return "date", c.forecastTTL
+	case q.Days > 0 || q.Hours > 0 || q.Alerts:
+		return "forecast", c.forecastTTL
+	}
+	return "current", c.currentTTL
+}
+
+func (c *Cache) get(ctx context.Context, endpoint, key string, ttl time.Duration, fetch func(context.Context) (any, error)) (any, error) {
+	attrs := metric.WithAttributes(attribute.String("weather.endpoint", endpoint), attribute.String("weather.provider", c.Name()))
+
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && c.now().Before(e.expiresAt) {
+		c.metrics.hit(ctx, attrs)
+		return e.value, nil
+	}
+
+	// the call is shared, so it must not be canceled with the caller that happened to start it
+	started := false
+	ch := c.group.DoChan(key, func() (any, error) {
+		started = true
+		ctx := context.WithoutCancel(ctx)
+		c.metrics.miss(ctx, attrs)
+		v, err := fetch(ctx)
+		if err == nil {
+			c.set(key, v, ttl)
+		}
+		return v, err
+	})
+
+	select {
+	case r := <-ch:
+		// r.Shared is also true for the caller that started the call
+		if !started {
+			c.metrics.hit(ctx, attrs)
+		}
+		return r.Val, r.Err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (c *Cache) set(key string, v any, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if len(c.entries) >= cacheSize {
+		for k, e := range c.entries {
+			if now.After(e.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+	}
+	// still full of fresh entries, any of them goes
+	for k := range c.entries {
+		if len(c.entries) < cacheSize {
+			break
+		}
+		delete(c.entries, k)
+	}
+	c.entries[key] = cacheEntry{value: v, expiresAt: now.Add(ttl)}
+}
+
+// normalizeLocation makes the spellings of a location share entries: resolved places by their
+// coordinates, names regardless of case and spaces.
+func normalizeLocation(q Query) string {
+	if q.Place != nil {
+		return fmt.Sprintf("%.4f,%.4f", q.Place.Lat, q.Place.Lon)
+	}
+	parts := strings.Split(strings.ToLower(q.Location), ",")
+	for i, p := range parts {
+		parts[i] = strings.Join(strings.Fields(p), " ")
+	}
+	return strings.Join(parts, ",")
+}
+
+func dateKey(date time.Time) string {
+	if date.IsZero() {
+		return ""
+	}
+	return date.Format(time.DateOnly)
+}
+
+type cacheMetrics struct {
+	hits   metric.Int64Counter
+	misses metric.Int64Counter
+	// totals of all caches for the hit ratio gauge
+	hitCount, missCount atomic.Int64
+}
+
+var sharedCacheMetrics = sync.OnceValue(newCacheMetrics)
+
+func newCacheMetrics() *cacheMetrics {
+	meter := otel.Meter("acai/weather")
+	m := &cacheMetrics{}
+
+	var err error
+	m.hits, err = meter.Int64Counter("weather.cache.hits",
+		metric.WithDescription("Number of weather requests served from the cache or coalesced with one in flight"),
+	)
+	if err != nil {
+		slog.Error("Failed to create weather.cache.hits counter", "error", err)
+	}
+
+	m.misses, err = meter.Int64Counter("weather.cache.misses",
+		metric.WithDescription("Number of weather requests sent to the provider"),
+	)
+	if err != nil {
+		slog.Error("Failed to create weather.cache.misses counter", "error", err)
+	}
+
+	_, err = meter.Float64ObservableGauge("weather.cache.hit_ratio",
+		metric.WithDescription("Share of weather requests served without calling the provider since start"),
+		metric.WithFloat64Callback(func(_ context.Context, o metric.Float64Observer) error {
+			hits, misses := m.hitCount.Load(), m.missCount.Load()
+			if hits+misses > 0 {
+				o.Observe(float64(hits) / float64(hits+misses))
+			}
+			return nil
+		}),
+	)
+	if err != nil {
+		slog.Error("Failed to create weather.cache.hit_ratio gauge", "error", err)
+	}
+
+	return m
+}
+
+func (m *cacheMetrics) hit(ctx context.Context, attrs metric.AddOption) {
+	m.hitCount.Add(1)
+	m.hits.Add(ctx, 1, attrs)
+}
+
+func (m *cacheMetrics) miss(ctx context.Context, attrs metric.AddOption) {
+	m.missCount.Add(1)
+	m.misses.Add(ctx, 1, attrs)
+}
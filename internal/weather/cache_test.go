@@ -0,0 +1,203 @@
+package weather
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type countingProvider struct {
+	calls    atomic.Int32
+	searches atomic.Int32
+	release  chan struct{}
+	err      error
+}
+
+func (p *countingProvider) Name() string { return "counting" }
+
+func (p *countingProvider) Fetch(_ context.Context, q Query) (Result, error) {
+	p.calls.Add(1)
+	if p.release != nil {
+		<-p.release
+	}
+	if p.err != nil {
+		return Result{}, p.err
+	}
+	return Result{Place: Geocode{Name: q.Location}, Provider: p.Name()}, nil
+}
+
+func (p *countingProvider) Search(_ context.Context, name string, _ int) ([]Geocode, error) {
+	p.searches.Add(1)
+	return []Geocode{{Name: name}}, nil
+}
+
+type clock struct{ t time.Time }
+
+func (c *clock) now() time.Time          { return c.t }
+func (c *clock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func testCache(p Provider) (*Cache, *clock) {
+	c := &clock{t: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	cache := NewCache(p, 10*time.Minute, time.Hour)
+	cache.now = c.now
+	return cache, c
+}
+
+func TestCache_TTLPerEndpoint(t *testing.T) {
+	p := &countingProvider{}
+	cache, clock := testCache(p)
+	ctx := context.Background()
+
+	fetch := func(q Query) {
+		t.Helper()
+		_, err := cache.Fetch(ctx, q)
+		require.NoError(t, err)
+	}
+
+	fetch(Query{Location: "Barcelona"})
+	fetch(Query{Location: "  barcelona "})
+	fetch(Query{Location: "Barcelona", Days: 3})
+	fetch(Query{Location: "BARCELONA", Days: 3})
+	require.Equal(t, int32(2), p.calls.Load(), "names are normalized, days are part of the key")
+
+	fetch(Query{Location: "Barcelona", Days: 3, Hours: 6})
+	fetch(Query{Location: "Barcelona", Place: &Geocode{Lat: 41.38879, Lon: 2.15899}})
+	fetch(Query{Location: "Barna", Place: &Geocode{Lat: 41.38879, Lon: 2.15899}})
+	require.Equal(t, int32(4), p.calls.Load(), "resolved places are keyed by coordinates")
+
+	clock.advance(11 * time.Minute)
+	fetch(Query{Location: "Barcelona"})
+	fetch(Query{Location: "Barcelona", Days: 3})
+	require.Equal(t, int32(5), p.calls.Load(), "current conditions expire before forecasts")
+
+	clock.advance(time.Hour)
+	fetch(Query{Location: "Barcelona", Days: 3})
+	require.Equal(t, int32(6), p.calls.Load())
+}
+
+func TestCache_Dates(t *testing.T) {
+	p := &countingProvider{}
+	cache, clock := testCache(p)
+	ctx := context.Background()
+	past := time.Now().AddDate(0, 0, -10)
+
+	for range 2 {
+		_, err := cache.Fetch(ctx, Query{Location: "Rome", Date: past})
+		require.NoError(t, err)
+		_, err = cache.Fetch(ctx, Query{Location: "Rome", Date: past.AddDate(0, 0, 1)})
+		require.NoError(t, err)
+	}
+	require.Equal(t, int32(2), p.calls.Load())
+
+	clock.advance(12 * time.Hour)
+	_, err := cache.Fetch(ctx, Query{Location: "Rome", Date: past})
+	require.NoError(t, err)
+	require.Equal(t, int32(2), p.calls.Load(), "observed days are kept longer than forecasts")
+}
+
+type horizonProvider struct {
+	countingProvider
+	days int
+}
+
+func (p *horizonProvider) forecastDays() int { return p.days }
+
+func TestCache_FutureFollowsProviderHorizon(t *testing.T) {
+	ctx := context.Background()
+	ahead := time.Now().AddDate(0, 0, 15)
+
+	for days, calls := range map[int]int32{14: 1, 16: 2} {
+		p := &horizonProvider{days: days}
+		cache, clock := testCache(p)
+
+		_, err := cache.Fetch(ctx, Query{Location: "Rome", Date: ahead})
+		require.NoError(t, err)
+		clock.advance(2 * time.Hour)
+		_, err = cache.Fetch(ctx, Query{Location: "Rome", Date: ahead})
+		require.NoError(t, err)
+		require.Equal(t, calls, p.calls.Load(), "horizon of %d days", days)
+	}
+
+	require.Equal(t, weatherAPIForecastDays, NewCache(Failover{NewWeatherAPI("key")}, 0, 0).horizon)
+	require.Equal(t, weatherAPIForecastDays, NewCache(Failover{NewWeatherAPI("key"), NewOpenMeteo()}, 0, 0).horizon)
+	require.Equal(t, openMeteoForecastDays, NewCache(Failover{NewOpenMeteo()}, 0, 0).horizon)
+}
+
+func TestCache_DoesNotKeepErrors(t *testing.T) {
+	p := &countingProvider{err: errors.New("upstream down")}
+	cache, _ := testCache(p)
+
+	for range 2 {
+		_, err := cache.Fetch(context.Background(), Query{Location: "Oslo"})
+		require.ErrorContains(t, err, "upstream down")
+	}
+	require.Equal(t, int32(2), p.calls.Load())
+}
+
+func TestCache_CoalescesConcurrentRequests(t *testing.T) {
+	p := &countingProvider{release: make(chan struct{})}
+	cache, _ := testCache(p)
+	hits, misses := cache.metrics.hitCount.Load(), cache.metrics.missCount.Load()
+
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := cache.Fetch(context.Background(), Query{Location: "Lisbon"})
+			require.NoError(t, err)
+			require.Equal(t, "Lisbon", res.Place.Name)
+		}()
+	}
+	require.Eventually(t, func() bool { return p.calls.Load() == 1 }, time.Second, time.Millisecond)
+	time.Sleep(10 * time.Millisecond) // let the other requests join the one in flight
+	close(p.release)
+	wg.Wait()
+
+	_, err := cache.Fetch(context.Background(), Query{Location: "Lisbon"})
+	require.NoError(t, err)
+	require.Equal(t, int32(1), p.calls.Load())
+	require.Equal(t, int64(5), cache.metrics.hitCount.Load()-hits)
+	require.Equal(t, int64(1), cache.metrics.missCount.Load()-misses)
+}
+
+func TestCache_CanceledCallerDoesNotCancelOthers(t *testing.T) {
+	p := &countingProvider{release: make(chan struct{})}
+	cache, _ := testCache(p)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		_, err := cache.Fetch(ctx, Query{Location: "Lisbon"})
+		done <- err
+	}()
+	require.Eventually(t, func() bool { return p.calls.Load() == 1 }, time.Second, time.Millisecond)
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+
+	close(p.release)
+	res, err := cache.Fetch(context.Background(), Query{Location: "Lisbon"})
+	require.NoError(t, err)
+	require.Equal(t, "Lisbon", res.Place.Name)
+	require.Equal(t, int32(1), p.calls.Load())
+}
+
+func TestCache_Search(t *testing.T) {
+	p := &countingProvider{}
+	cache, _ := testCache(p)
+
+	for _, name := range []string{"Santiago", "santiago ", "Santiago"} {
+		found, err := cache.Search(context.Background(), name, 10)
+		require.NoError(t, err)
+		require.Len(t, found, 1)
+	}
+	require.Equal(t, int32(1), p.searches.Load())
+
+	_, err := NewCache(Failover{NewWeatherAPI("key")}, 0, 0).Search(context.Background(), "Santiago", 10)
+	require.ErrorIs(t, err, ErrNoGeocoder)
+}
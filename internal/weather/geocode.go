@@ -0,0 +1,138 @@
+package weather
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Geocoder searches places by name, best matches first.
+type Geocoder interface {
+	Search(ctx context.Context, name string, limit int) ([]Geocode, error)
+}
+
+var ErrNoGeocoder = errors.New("no geocoder configured")
+
+const (
+	searchLimit = 10
+	// maxCandidates is the number of candidates returned for an ambiguous location.
+	maxCandidates = 5
+)
+
+// Label is the name of the place with its region and country, it resolves to the same place again.
+func (g Geocode) Label() string {
+	parts := []string{g.Name}
+	if g.Region != "" && g.Region != g.Name {
+		parts = append(parts, g.Region)
+	}
+	if g.Country != "" {
+		parts = append(parts, g.Country)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Resolution is the outcome of Resolve.
+type Resolution struct {
+	// Place is the place the location refers to, nil when it is ambiguous.
+	Place *Geocode
+	// Candidates are the notable places matching the location, best first. When Place is set they are
+	// the first one and the alternatives the user may have meant.
+	Candidates []Geocode
+}
+
+// Resolve finds the place a location refers to. The location is a name optionally followed by its
+// region and/or country ("Santiago, Chile"), or coordinates ("41.39,2.17"). When other matching places
+// are about as well known as the first one, the location is ambiguous and only candidates are returned.
+func Resolve(ctx context.Context, g Geocoder, location string) (Resolution, error) {
+	if place, ok := parseCoordinates(location); ok {
+		return Resolution{Place: &place, Candidates: []Geocode{place}}, nil
+	}
+
+	name, qualifiers := splitLocation(location)
+	if name == "" {
+		return Resolution{}, fmt.Errorf("invalid location %q", location)
+	}
+	found, err := g.Search(ctx, name, searchLimit)
+	if err != nil {
+		return Resolution{}, err
+	}
+
+	var candidates []Geocode
+	for _, c := range found {
+		if matchesAll(c, qualifiers) {
+			candidates = append(candidates, c)
+		}
+	}
+	if len(candidates) == 0 {
+		return Resolution{}, fmt.Errorf("no matching location found for %q", location)
+	}
+
+	first := candidates[0]
+	res := Resolution{Candidates: []Geocode{first}}
+	ambiguous := false
+	for _, c := range candidates[1:] {
+		if len(res.Candidates) == maxCandidates {
+			break
+		}
+		if c.Region == first.Region && c.Country == first.Country {
+			continue
+		}
+		if comparable(c, first, notableShare) {
+			res.Candidates = append(res.Candidates, c)
+			ambiguous = ambiguous || comparable(c, first, ambiguousShare)
+		}
+	}
+	if !ambiguous {
+		res.Place = &first
+	}
+	return res, nil
+}
+
+// A place is notable next to the first one with a tenth of its population, and makes the location
+// ambiguous with half of it. Places of unknown population are always both.
+const (
+	notableShare   = 10
+	ambiguousShare = 2
+)
+
+func comparable(c, first Geocode, share int) bool {
+	if c.Population == 0 || first.Population == 0 {
+		return true
+	}
+	return c.Population*share >= first.Population
+}
+
+func splitLocation(location string) (string, []string) {
+	parts := strings.Split(location, ",")
+	var qualifiers []string
+	for _, q := range parts[1:] {
+		if q = strings.TrimSpace(q); q != "" {
+			qualifiers = append(qualifiers, q)
+		}
+	}
+	return strings.TrimSpace(parts[0]), qualifiers
+}
+
+func matchesAll(place Geocode, qualifiers []string) bool {
+	for _, q := range qualifiers {
+		if !strings.EqualFold(q, place.Region) && !strings.EqualFold(q, place.Country) && !strings.EqualFold(q, place.CountryCode) {
+			return false
+		}
+	}
+	return true
+}
+
+func parseCoordinates(location string) (Geocode, bool) {
+	lat, lon, ok := strings.Cut(location, ",")
+	if !ok {
+		return Geocode{}, false
+	}
+	la, err1 := strconv.ParseFloat(strings.TrimSpace(lat), 64)
+	lo, err2 := strconv.ParseFloat(strings.TrimSpace(lon), 64)
+	if err1 != nil || err2 != nil || la < -90 || la > 90 || lo < -180 || lo > 180 {
+		return Geocode{}, false
+	}
+	return Geocode{Name: strings.TrimSpace(location), Lat: la, Lon: lo}, true
+}
@@ -0,0 +1,108 @@
+package weather_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/weather"
+	"github.com/stretchr/testify/require"
+)
+
+type geocoderFunc func(ctx context.Context, name string, limit int) ([]weather.Geocode, error)
+
+func (f geocoderFunc) Search(ctx context.Context, name string, limit int) ([]weather.Geocode, error) {
+	return f(ctx, name, limit)
+}
+
+var places = map[string][]weather.Geocode{
+	"Paris": {
+		{Name: "Paris", Region: "Île-de-France", Country: "France", CountryCode: "FR", Population: 2138551},
+		{Name: "Paris", Region: "Texas", Country: "United States", CountryCode: "US", Population: 25171},
+	},
+	"Barcelona": {
+		{Name: "Barcelona", Region: "Catalonia", Country: "Spain", CountryCode: "ES", Population: 1620343},
+		{Name: "Barcelona", Region: "Anzoátegui", Country: "Venezuela", CountryCode: "VE", Population: 424795},
+	},
+	"Santiago": {
+		{Name: "Santiago", Region: "Santiago Metropolitan", Country: "Chile", CountryCode: "CL", Population: 4837295},
+		{Name: "Santiago", Region: "Santiago", Country: "Dominican Republic", CountryCode: "DO", Population: 3000000},
+		{Name: "Santiago", Region: "Galicia", Country: "Spain", CountryCode: "ES", Population: 95092},
+	},
+	"Springfield": {
+		{Name: "Springfield", Region: "Missouri", Country: "United States", CountryCode: "US", Population: 166810},
+		{Name: "Springfield", Region: "Massachusetts", Country: "United States", CountryCode: "US", Population: 155929},
+		{Name: "Springfield", Region: "Illinois", Country: "United States", CountryCode: "US", Population: 116250},
+	},
+}
+
+func TestResolve(t *testing.T) {
+	g := geocoderFunc(func(_ context.Context, name string, _ int) ([]weather.Geocode, error) {
+		if name == "Gotham" {
+			return nil, errors.New("geocoding is down")
+		}
+		return places[name], nil
+	})
+
+	tests := []struct {
+		location   string
+		place      string
+		candidates []string
+		err        string
+	}{
+		{location: "Paris", place: "Paris, Île-de-France, France", candidates: []string{"Paris, Île-de-France, France"}},
+		{location: "barcelona", err: `no matching location found for "barcelona"`},
+		{location: "Barcelona", place: "Barcelona, Catalonia, Spain", candidates: []string{
+			"Barcelona, Catalonia, Spain", "Barcelona, Anzoátegui, Venezuela",
+		}},
+		{location: "Santiago", candidates: []string{
+			"Santiago, Santiago Metropolitan, Chile", "Santiago, Dominican Republic",
+		}},
+		{location: "Santiago, Chile", place: "Santiago, Santiago Metropolitan, Chile", candidates: []string{"Santiago, Santiago Metropolitan, Chile"}},
+		{location: "Santiago , es", place: "Santiago, Galicia, Spain", candidates: []string{"Santiago, Galicia, Spain"}},
+		{location: "Springfield, United States", candidates: []string{
+			"Springfield, Missouri, United States", "Springfield, Massachusetts, United States", "Springfield, Illinois, United States",
+		}},
+		{location: "Springfield, Illinois, US", place: "Springfield, Illinois, United States", candidates: []string{"Springfield, Illinois, United States"}},
+		{location: "41.39, 2.17", place: "41.39, 2.17", candidates: []string{"41.39, 2.17"}},
+		{location: "Paris, Germany", err: `no matching location found for "Paris, Germany"`},
+		{location: "Gotham", err: "geocoding is down"},
+		{location: ", Spain", err: "invalid location"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.location, func(t *testing.T) {
+			res, err := weather.Resolve(context.Background(), g, tt.location)
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+
+			if tt.place == "" {
+				require.Nil(t, res.Place)
+			} else {
+				require.NotNil(t, res.Place)
+				require.Equal(t, tt.place, res.Place.Label())
+			}
+			var labels []string
+			for _, c := range res.Candidates {
+				labels = append(labels, c.Label())
+			}
+			require.Equal(t, tt.candidates, labels)
+		})
+	}
+}
+
+func TestFailover_Search(t *testing.T) {
+	_, err := weather.Failover{weather.NewWeatherAPI("key")}.Search(context.Background(), "Paris", 5)
+	require.ErrorIs(t, err, weather.ErrNoGeocoder)
+
+	ts := openMeteoServer(t)
+	f := weather.Failover{weather.NewWeatherAPI("key"), weather.NewOpenMeteo(weather.WithBaseURL(ts.URL), weather.WithHTTPClient(ts.Client()))}
+	found, err := f.Search(context.Background(), "Lisbon", 5)
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	require.Equal(t, "Lisbon, Lisbon, Portugal", found[0].Name+", "+found[0].Region+", "+found[0].Country)
+	require.Equal(t, "PT", found[0].CountryCode)
+	require.Equal(t, 517802, found[0].Population)
+}
@@ -0,0 +1,470 @@
+package weather
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// OpenMeteo is the client of https://open-meteo.com, it needs no API key. Locations are resolved with
+// its geocoding API first.
+type OpenMeteo struct {
+	forecastURL   string
+	archiveURL    string
+	geocodingURL  string
+	airQualityURL string
+	client        *http.Client
+}
+
+func NewOpenMeteo(opts ...Option) *OpenMeteo {
+	o := newOptions("", opts)
+	m := &OpenMeteo{
+		forecastURL:   "https://api.open-meteo.com/v1/forecast",
+		archiveURL:    "https://archive-api.open-meteo.com/v1/archive",
+		geocodingURL:  "https://geocoding-api.open-meteo.com/v1/search",
+		airQualityURL: "https://air-quality-api.open-meteo.com/v1/air-quality",
+		client:        o.client,
+	}
+	if o.baseURL != "" {
+		m.forecastURL = o.baseURL + "/v1/forecast"
+		m.archiveURL = o.baseURL + "/v1/archive"
+		m.geocodingURL = o.baseURL + "/v1/search"
+		m.airQualityURL = o.baseURL + "/v1/air-quality"
+	}
+	return m
+}
+
+func (m *OpenMeteo) Name() string { return "openmeteo" }
+
+func (m *OpenMeteo) forecastDays() int { return openMeteoForecastDays }
+
+func (m *OpenMeteo) Fetch(ctx context.Context, q Query) (Result, error) {
+	var place Geocode
+	if q.Place != nil {
+		place = *q.Place
+	} else {
+		found, err := m.Search(ctx, q.Location, 1)
+		if err != nil {
+			return Result{}, err
+		}
+		if len(found) == 0 {
+			return Result{}, fmt.Errorf("openmeteo: no matching location found for %q", q.Location)
+		}
+		place = found[0]
+	}
+	if !q.Date.IsZero() {
+		return m.fetchDays(ctx, place, q.Date, q.lastDate())
+	}
+
+	days := clampDays(q.Days)
+	params := url.Values{
+		"latitude":  {strconv.FormatFloat(place.Lat, 'f', 4, 64)},
+		"longitude": {strconv.FormatFloat(place.Lon, 'f', 4, 64)},
+		"current":   {"temperature_2m,wind_speed_10m,wind_direction_10m,weather_code"},
+		"timezone":  {"auto"},
+	}
+	if days > 0 {
+		params.Set("daily", openMeteoDailyFields)
+		params.Set("forecast_days", strconv.Itoa(days))
+	}
+	hours := clampHours(q.Hours)
+	if hours > 0 {
+		params.Set("hourly", "temperature_2m,weather_code,precipitation_probability,precipitation,wind_speed_10m")
+		params.Set("forecast_hours", strconv.Itoa(hours))
+	}
+
+	slog.InfoContext(ctx, "Fetching real weather from Open-Meteo...", "location", q.Location, "days", days)
+
+	var data struct {
+		Current struct {
+			Temperature   float64 `json:"temperature_2m"`
+			WindSpeed     float64 `json:"wind_speed_10m"`
+			WindDirection float64 `json:"wind_direction_10m"`
+			WeatherCode   int     `json:"weather_code"`
+		} `json:"current"`
+		Timezone  string         `json:"timezone"`
+		UTCOffset int            `json:"utc_offset_seconds"`
+		Daily     openMeteoDaily `json:"daily"`
+		Hourly    struct {
+			Time          []string  `json:"time"`
+			Temperature   []float64 `json:"temperature_2m"`
+			WeatherCode   []int     `json:"weather_code"`
+			Probability   []int     `json:"precipitation_probability"`
+			Precipitation []float64 `json:"precipitation"`
+			WindSpeed     []float64 `json:"wind_speed_10m"`
+		} `json:"hourly"`
+	}
+	if err := m.get(ctx, m.forecastURL, params, &data); err != nil {
+		return Result{}, err
+	}
+
+	res := Result{
+		Place: place,
+		Current: Current{
+			TemperatureC: data.Current.Temperature,
+			WindSpeedKmh: data.Current.WindSpeed,
+			WindDirDeg:   data.Current.WindDirection,
+			Condition:    wmoCondition(data.Current.WeatherCode),
+		},
+		Provider: m.Name(),
+	}
+
+	res.Forecast = data.Daily.days()
+
+	h := data.Hourly
+	zone := placeZone(data.Timezone, data.UTCOffset)
+	for i, hour := range h.Time {
+		if i >= hours || i >= len(h.Temperature) || i >= len(h.WeatherCode) || i >= len(h.Probability) ||
+			i >= len(h.Precipitation) || i >= len(h.WindSpeed) {
+			break
+		}
+		t, _ := time.ParseInLocation("2006-01-02T15:04", hour, zone)
+		res.Hourly = append(res.Hourly, HourlyForecast{
+			Time:         t,
+			TempC:        h.Temperature[i],
+			Condition:    wmoCondition(h.WeatherCode[i]),
+			ChanceOfRain: h.Probability[i],
+			PrecipMM:     h.Precipitation[i],
+			WindKmh:      h.WindSpeed[i],
+		})
+	}
+
+	if q.Alerts {
+		res.Unsupported = append(res.Unsupported, "alerts")
+	}
+	if q.AirQuality {
+		var err error
+		if res.AirQuality, err = m.airQuality(ctx, place); err != nil {
+			// the weather is still useful without it
+			slog.WarnContext(ctx, "Open-Meteo air quality failed", "error", err)
+			res.Unsupported = append(res.Unsupported, "air quality")
+		}
+	}
+
+	return res, nil
+}
+
+const (
+	// openMeteoForecastDays is how far ahead the forecast API goes.
+	openMeteoForecastDays = 16
+	// openMeteoArchiveDelay is how many days the reanalysis of the archive API lags behind.
+	openMeteoArchiveDelay = 5
+	// climateWindow is the number of days around the date averaged in each year.
+	climateWindow = 3
+
+	openMeteoDailyFields = "weather_code,temperature_2m_max,temperature_2m_min,wind_speed_10m_max,precipitation_sum"
+)
+
+// fetchDays reads past dates from the archive (the last days from the forecast API), dates in the
+// forecast range from the forecast and averages the same days of the past years for later ones. Each of
+// these spans of the range is a single request.
+func (m *OpenMeteo) fetchDays(ctx context.Context, place Geocode, from, to time.Time) (Result, error) {
+	res := Result{Place: place, Provider: m.Name()}
+	for start := from; !start.After(to); {
+		endpoint, _ := m.source(start)
+		end := start
+		for next := end.AddDate(0, 0, 1); !next.After(to); next = next.AddDate(0, 0, 1) {
+			if e, _ := m.source(next); e != endpoint {
+				break
+			}
+			end = next
+		}
+
+		span := start.Format(time.DateOnly)
+		if end.After(start) {
+			span += ".." + end.Format(time.DateOnly)
+		}
+
+		var days []DailyForecast
+		if endpoint == "" {
+			var err error
+			if days, res.Note, err = m.climate(ctx, place, start, end); err != nil {
+				return Result{}, err
+			}
+		} else {
+			_, kind := m.source(start)
+			slog.InfoContext(ctx, "Fetching real weather from Open-Meteo...", "location", place.Name, "date", span, "kind", kind)
+			daily, err := m.daily(ctx, endpoint, place, start.Format(time.DateOnly), end.Format(time.DateOnly))
+			if err != nil {
+				return Result{}, err
+			}
+			for _, d := range daily.days() {
+				_, d.Kind = m.source(d.Date)
+				days = append(days, d)
+			}
+		}
+		if len(days) == 0 {
+			return Result{}, fmt.Errorf("openmeteo: no data for %s", span)
+		}
+
+		res.Forecast = append(res.Forecast, days...)
+		start = end.AddDate(0, 0, 1)
+	}
+	res.Kind = res.Forecast[0].Kind
+	return res, nil
+}
+
+// source is the endpoint serving date, empty for the days averaged from the past years, and how the
+// weather of the day is known.
+func (m *OpenMeteo) source(date time.Time) (string, DataKind) {
+	switch delta := daysFromToday(date); {
+	case delta < -openMeteoArchiveDelay:
+		return m.archiveURL, KindObserved
+	case delta < 0:
+		return m.forecastURL, KindObserved
+	case delta >= openMeteoForecastDays:
+		return "", KindClimatological
+	}
+	return m.forecastURL, KindForecast
+}
+
+// climate averages the days around each date of the range over the last climateYears years in the
+// archive, with a single request.
+func (m *OpenMeteo) climate(ctx context.Context, place Geocode, from, to time.Time) ([]DailyForecast, string, error) {
+	// the last averaged year is back years before the range
+	back := 0
+	for daysFromToday(to.AddDate(-back, 0, climateWindow)) >= -openMeteoArchiveDelay {
+		back++
+	}
+	last := to.Year() - back
+	first := last - climateYears + 1
+
+	slog.InfoContext(ctx, "Fetching climate from Open-Meteo...", "location", place.Name, "date", from.Format(time.DateOnly), "years", fmt.Sprintf("%d-%d", first, last))
+	daily, err := m.daily(ctx, m.archiveURL, place,
+		from.AddDate(-back-climateYears+1, 0, -climateWindow).Format(time.DateOnly),
+		to.AddDate(-back, 0, climateWindow).Format(time.DateOnly))
+	if err != nil {
+		return nil, "", err
+	}
+	past := map[string]DailyForecast{}
+	for _, d := range daily.days() {
+		past[d.Date.Format(time.DateOnly)] = d
+	}
+
+	var out []DailyForecast
+	for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+		var (
+			avg        = DailyForecast{Date: date, Kind: KindClimatological}
+			n          int
+			conditions = map[string]int{}
+		)
+		for year := back; year < back+climateYears; year++ {
+			for offset := -climateWindow; offset <= climateWindow; offset++ {
+				d, ok := past[date.AddDate(-year, 0, offset).Format(time.DateOnly)]
+				if !ok {
+					continue
+				}
+				avg.MinTempC += d.MinTempC
+				avg.MaxTempC += d.MaxTempC
+				avg.WindMaxKmh += d.WindMaxKmh
+				avg.PrecipMM += d.PrecipMM
+				conditions[d.Condition]++
+				n++
+			}
+		}
+		if n == 0 {
+			return nil, "", fmt.Errorf("openmeteo: no climate data for %s", date.Format(time.DateOnly))
+		}
+
+		avg.MinTempC /= float64(n)
+		avg.MaxTempC /= float64(n)
+		avg.WindMaxKmh /= float64(n)
+		avg.PrecipMM /= float64(n)
+		for condition, count := range conditions {
+			if count > conditions[avg.Condition] || count == conditions[avg.Condition] && condition < avg.Condition {
+				avg.Condition = condition
+			}
+		}
+		out = append(out, avg)
+	}
+
+	days := from.Format("2 January")
+	if to.After(from) {
+		days = "each day"
+	}
+	return out, fmt.Sprintf("average of %s ±%d days over %d-%d, the condition is the most frequent one",
+		days, climateWindow, first, last), nil
+}
+
+func (m *OpenMeteo) daily(ctx context.Context, endpoint string, place Geocode, from, to string) (openMeteoDaily, error) {
+	var data struct {
+		Daily openMeteoDaily `json:"daily"`
+	}
+	params := url.Values{
+		"latitude":   {strconv.FormatFloat(place.Lat, 'f', 4, 64)},
+		"longitude":  {strconv.FormatFloat(place.Lon, 'f', 4, 64)},
+		"daily":      {openMeteoDailyFields},
+		"start_date": {from},
+		"end_date":   {to},
+		"timezone":   {"auto"},
+	}
+	err := m.get(ctx, endpoint, params, &data)
+	return data.Daily, err
+}
+
+type openMeteoDaily struct {
+	Time        []string  `json:"time"`
+	WeatherCode []int     `json:"weather_code"`
+	MaxTemp     []float64 `json:"temperature_2m_max"`
+	MinTemp     []float64 `json:"temperature_2m_min"`
+	MaxWind     []float64 `json:"wind_speed_10m_max"`
+	Precip      []float64 `json:"precipitation_sum"`
+}
+
+func (d openMeteoDaily) days() []DailyForecast {
+	var days []DailyForecast
+	for i, day := range d.Time {
+		if i >= len(d.WeatherCode) || i >= len(d.MaxTemp) || i >= len(d.MinTemp) || i >= len(d.MaxWind) {
+			break
+		}
+		dt, _ := time.Parse(time.DateOnly, day)
+		f := DailyForecast{
+			Date:       dt,
+			MinTempC:   d.MinTemp[i],
+			MaxTempC:   d.MaxTemp[i],
+			WindMaxKmh: d.MaxWind[i],
+			Condition:  wmoCondition(d.WeatherCode[i]),
+		}
+		if i < len(d.Precip) {
+			f.PrecipMM = d.Precip[i]
+		}
+		days = append(days, f)
+	}
+	return days
+}
+
+func (m *OpenMeteo) airQuality(ctx context.Context, place Geocode) (*AirQuality, error) {
+	var data struct {
+		Current struct {
+			USAQI float64 `json:"us_aqi"`
+			PM25  float64 `json:"pm2_5"`
+			PM10  float64 `json:"pm10"`
+			O3    float64 `json:"ozone"`
+			NO2   float64 `json:"nitrogen_dioxide"`
+		} `json:"current"`
+	}
+	params := url.Values{
+		"latitude":  {strconv.FormatFloat(place.Lat, 'f', 4, 64)},
+		"longitude": {strconv.FormatFloat(place.Lon, 'f', 4, 64)},
+		"current":   {"us_aqi,pm2_5,pm10,ozone,nitrogen_dioxide"},
+	}
+	if err := m.get(ctx, m.airQualityURL, params, &data); err != nil {
+		return nil, err
+	}
+
+	c := data.Current
+	aqi := int(c.USAQI + 0.5)
+	return &AirQuality{Category: usAQICategory(aqi), USAQI: aqi, PM25: c.PM25, PM10: c.PM10, O3: c.O3, NO2: c.NO2}, nil
+}
+
+// Search looks places up with the geocoding API, ranked by relevance and population.
+func (m *OpenMeteo) Search(ctx context.Context, name string, limit int) ([]Geocode, error) {
+	var data struct {
+		Results []struct {
+			Name        string  `json:"name"`
+			Admin1      string  `json:"admin1"`
+			Country     string  `json:"country"`
+			CountryCode string  `json:"country_code"`
+			Latitude    float64 `json:"latitude"`
+			Longitude   float64 `json:"longitude"`
+			Population  int     `json:"population"`
+		} `json:"results"`
+	}
+	params := url.Values{"name": {name}, "count": {strconv.Itoa(limit)}, "language": {"en"}, "format": {"json"}}
+	if err := m.get(ctx, m.geocodingURL, params, &data); err != nil {
+		return nil, err
+	}
+
+	places := make([]Geocode, 0, len(data.Results))
+	for _, r := range data.Results {
+		places = append(places, Geocode{
+			Name:        r.Name,
+			Region:      r.Admin1,
+			Country:     r.Country,
+			CountryCode: r.CountryCode,
+			Lat:         r.Latitude,
+			Lon:         r.Longitude,
+			Population:  r.Population,
+		})
+	}
+	return places, nil
+}
+
+func (m *OpenMeteo) get(ctx context.Context, endpoint string, params url.Values, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"?"+params.Encode(), nil)
+	if err != nil {
+		return requestError("openmeteo", err)
+	}
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return requestError("openmeteo", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Reason string `json:"reason"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&apiErr) == nil && apiErr.Reason != "" {
+			return fmt.Errorf("openmeteo: %s", apiErr.Reason)
+		}
+		return fmt.Errorf("openmeteo error: %s", resp.Status)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("openmeteo: invalid response: %w", err)
+	}
+	return nil
+}
+
+// wmoCondition describes a WMO weather interpretation code as used by Open-Meteo.
+func wmoCondition(code int) string {
+	switch code {
+	case 0:
+		return "Clear sky"
+	case 1:
+		return "Mainly clear"
+	case 2:
+		return "Partly cloudy"
+	case 3:
+		return "Overcast"
+	case 45, 48:
+		return "Fog"
+	case 51, 53, 55:
+		return "Drizzle"
+	case 56, 57:
+		return "Freezing drizzle"
+	case 61:
+		return "Light rain"
+	case 63:
+		return "Moderate rain"
+	case 65:
+		return "Heavy rain"
+	case 66, 67:
+		return "Freezing rain"
+	case 71:
+		return "Light snow"
+	case 73:
+		return "Moderate snow"
+	case 75:
+		return "Heavy snow"
+	case 77:
+		return "Snow grains"
+	case 80, 81:
+		return "Rain showers"
+	case 82:
+		return "Violent rain showers"
+	case 85, 86:
+		return "Snow showers"
+	case 95:
+		return "Thunderstorm"
+	case 96, 99:
+		return "Thunderstorm with hail"
+	}
+	return "Unknown"
+}
@@ -0,0 +1,215 @@
+package weather_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/matteo-nyapa/tech-challenge-acai/internal/weather"
+	"github.com/stretchr/testify/require"
+)
+
+func openMeteoServer(t *testing.T) *httptest.Server {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/search":
+			if r.URL.Query().Get("name") != "Lisbon" {
+				_ = json.NewEncoder(w).Encode(map[string]any{"generationtime_ms": 0.5})
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"results": []any{map[string]any{
+				"name": "Lisbon", "admin1": "Lisbon", "country": "Portugal", "country_code": "PT",
+				"latitude": 38.71667, "longitude": -9.13333, "population": 517802,
+			}}})
+		case "/v1/forecast":
+			require.Equal(t, "38.7167", r.URL.Query().Get("latitude"))
+			resp := map[string]any{"current": map[string]any{
+				"temperature_2m": 19.4, "wind_speed_10m": 12.2, "wind_direction_10m": 310, "weather_code": 2,
+			}}
+			if r.URL.Query().Get("forecast_days") == "2" {
+				resp["daily"] = map[string]any{
+					"time":               []string{"2025-06-20", "2025-06-21"},
+					"weather_code":       []int{0, 63},
+					"temperature_2m_max": []float64{27.1, 22.3},
+					"temperature_2m_min": []float64{17.0, 16.2},
+					"wind_speed_10m_max": []float64{18.0, 30.5},
+				}
+			}
+			if r.URL.Query().Get("forecast_hours") == "2" {
+				resp["hourly"] = map[string]any{
+					"time":                      []string{"2025-06-20T14:00", "2025-06-20T15:00"},
+					"temperature_2m":            []float64{26.0, 25.1},
+					"weather_code":              []int{1, 80},
+					"precipitation_probability": []int{10, 70},
+					"precipitation":             []float64{0, 1.2},
+					"wind_speed_10m":            []float64{14.0, 21.5},
+				}
+			}
+			_ = json.NewEncoder(w).Encode(resp)
+		case "/v1/air-quality":
+			require.Equal(t, "-9.1333", r.URL.Query().Get("longitude"))
+			_ = json.NewEncoder(w).Encode(map[string]any{"current": map[string]any{
+				"us_aqi": 57.4, "pm2_5": 11.3, "pm10": 20.0, "ozone": 88.0, "nitrogen_dioxide": 14.2,
+			}})
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]any{"error": true, "reason": "unknown endpoint"})
+		}
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestOpenMeteo_Current(t *testing.T) {
+	ts := openMeteoServer(t)
+	om := weather.NewOpenMeteo(weather.WithBaseURL(ts.URL), weather.WithHTTPClient(ts.Client()))
+
+	res, err := om.Fetch(context.Background(), weather.Query{Location: "Lisbon"})
+	require.NoError(t, err)
+	require.Equal(t, "Lisbon", res.Place.Name)
+	require.Equal(t, "Portugal", res.Place.Country)
+	require.InDelta(t, 19.4, res.Current.TemperatureC, 0.01)
+	require.Equal(t, "Partly cloudy", res.Current.Condition)
+	require.Equal(t, "openmeteo", res.Provider)
+	require.Empty(t, res.Forecast)
+}
+
+func TestOpenMeteo_Forecast(t *testing.T) {
+	ts := openMeteoServer(t)
+	om := weather.NewOpenMeteo(weather.WithBaseURL(ts.URL), weather.WithHTTPClient(ts.Client()))
+
+	res, err := om.Fetch(context.Background(), weather.Query{Location: "Lisbon", Days: 2})
+	require.NoError(t, err)
+	require.Len(t, res.Forecast, 2)
+	require.Equal(t, "Clear sky", res.Forecast[0].Condition)
+	require.Equal(t, "Moderate rain", res.Forecast[1].Condition)
+	require.InDelta(t, 16.2, res.Forecast[1].MinTempC, 0.01)
+	require.InDelta(t, 30.5, res.Forecast[1].WindMaxKmh, 0.01)
+}
+
+func TestOpenMeteo_HourlyAndAirQuality(t *testing.T) {
+	ts := openMeteoServer(t)
+	om := weather.NewOpenMeteo(weather.WithBaseURL(ts.URL), weather.WithHTTPClient(ts.Client()))
+
+	res, err := om.Fetch(context.Background(), weather.Query{Location: "Lisbon", Hours: 2, Alerts: true, AirQuality: true})
+	require.NoError(t, err)
+	require.Len(t, res.Hourly, 2)
+	require.Equal(t, "2025-06-20 15:00", res.Hourly[1].Time.Format("2006-01-02 15:04"))
+	require.Equal(t, "Rain showers", res.Hourly[1].Condition)
+	require.Equal(t, 70, res.Hourly[1].ChanceOfRain)
+	require.InDelta(t, 1.2, res.Hourly[1].PrecipMM, 0.01)
+
+	require.NotNil(t, res.AirQuality)
+	require.Equal(t, 57, res.AirQuality.USAQI)
+	require.Equal(t, "Moderate", res.AirQuality.Category)
+	require.InDelta(t, 11.3, res.AirQuality.PM25, 0.01)
+
+	require.Nil(t, res.Alerts)
+	require.Equal(t, []string{"alerts"}, res.Unsupported)
+}
+
+func TestOpenMeteo_UnknownLocation(t *testing.T) {
+	ts := openMeteoServer(t)
+	om := weather.NewOpenMeteo(weather.WithBaseURL(ts.URL), weather.WithHTTPClient(ts.Client()))
+
+	_, err := om.Fetch(context.Background(), weather.Query{Location: "Atlantis"})
+	require.ErrorContains(t, err, "no matching location")
+}
+
+func TestOpenMeteo_Date(t *testing.T) {
+	var paths []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/search" {
+			_ = json.NewEncoder(w).Encode(map[string]any{"results": []any{map[string]any{"name": "Rome", "country": "Italy"}}})
+			return
+		}
+		paths = append(paths, r.URL.Path)
+
+		// every day of the range, the max temperature goes from 20 to 29 with the last digit of the year
+		from, _ := time.Parse(time.DateOnly, r.URL.Query().Get("start_date"))
+		to, _ := time.Parse(time.DateOnly, r.URL.Query().Get("end_date"))
+		daily := map[string][]any{}
+		for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+			daily["time"] = append(daily["time"], d.Format(time.DateOnly))
+			daily["weather_code"] = append(daily["weather_code"], 3)
+			daily["temperature_2m_max"] = append(daily["temperature_2m_max"], 20+d.Year()%10)
+			daily["temperature_2m_min"] = append(daily["temperature_2m_min"], 10)
+			daily["wind_speed_10m_max"] = append(daily["wind_speed_10m_max"], 14)
+			daily["precipitation_sum"] = append(daily["precipitation_sum"], 0.7)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"daily": daily})
+	}))
+	t.Cleanup(ts.Close)
+	om := weather.NewOpenMeteo(weather.WithBaseURL(ts.URL), weather.WithHTTPClient(ts.Client()))
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	tests := []struct {
+		name string
+		date time.Time
+		path string
+		kind weather.DataKind
+	}{
+		{"last month", today.AddDate(0, -1, 0), "/v1/archive", weather.KindObserved},
+		{"yesterday", today.AddDate(0, 0, -1), "/v1/forecast", weather.KindObserved},
+		{"in three days", today.AddDate(0, 0, 3), "/v1/forecast", weather.KindForecast},
+		{"next season", today.AddDate(0, 4, 0), "/v1/archive", weather.KindClimatological},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paths = nil
+			res, err := om.Fetch(context.Background(), weather.Query{Location: "Rome", Date: tt.date})
+			require.NoError(t, err)
+			require.Equal(t, []string{tt.path}, paths)
+			require.Equal(t, tt.kind, res.Kind)
+			require.Len(t, res.Forecast, 1)
+			require.Equal(t, tt.date.Format(time.DateOnly), res.Forecast[0].Date.Format(time.DateOnly))
+			require.Equal(t, "Overcast", res.Forecast[0].Condition)
+			require.InDelta(t, 0.7, res.Forecast[0].PrecipMM, 0.001)
+		})
+	}
+
+	res, err := om.Fetch(context.Background(), weather.Query{Location: "Rome", Date: today.AddDate(0, 4, 0)})
+	require.NoError(t, err)
+	require.InDelta(t, 24.5, res.Forecast[0].MaxTempC, 0.001, "average of 10 consecutive years")
+	require.Contains(t, res.Note, "±3 days")
+}
+
+func TestOpenMeteo_DateRange(t *testing.T) {
+	var paths []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		from, _ := time.Parse(time.DateOnly, r.URL.Query().Get("start_date"))
+		to, _ := time.Parse(time.DateOnly, r.URL.Query().Get("end_date"))
+		daily := map[string][]any{}
+		for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+			daily["time"] = append(daily["time"], d.Format(time.DateOnly))
+			daily["weather_code"] = append(daily["weather_code"], 0)
+			daily["temperature_2m_max"] = append(daily["temperature_2m_max"], 25)
+			daily["temperature_2m_min"] = append(daily["temperature_2m_min"], 15)
+			daily["wind_speed_10m_max"] = append(daily["wind_speed_10m_max"], 10)
+			daily["precipitation_sum"] = append(daily["precipitation_sum"], 0)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"daily": daily})
+	}))
+	t.Cleanup(ts.Close)
+	om := weather.NewOpenMeteo(weather.WithBaseURL(ts.URL), weather.WithHTTPClient(ts.Client()))
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	res, err := om.Fetch(context.Background(), weather.Query{
+		Place:   &weather.Geocode{Name: "Rome"},
+		Date:    today.AddDate(0, 0, 14),
+		EndDate: today.AddDate(0, 0, 20),
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"/v1/forecast", "/v1/archive"}, paths, "one request per span of the range")
+	require.Len(t, res.Forecast, 7)
+	require.Equal(t, weather.KindForecast, res.Kind)
+	require.Equal(t, weather.KindForecast, res.Forecast[1].Kind)
+	require.Equal(t, weather.KindClimatological, res.Forecast[2].Kind)
+	require.Equal(t, today.AddDate(0, 0, 20), res.Forecast[6].Date)
+	require.InDelta(t, 25, res.Forecast[6].MaxTempC, 0.001)
+	require.Contains(t, res.Note, "average of each day ±3 days")
+}
@@ -2,21 +2,25 @@ package weather
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"net/url"
 	"os"
+	"strings"
 	"time"
 )
 
 type Geocode struct {
-	Name    string
-	Country string
-	Lat     float64
-	Lon     float64
+	Name        string
+	Region      string
+	Country     string
+	CountryCode string
+	Lat         float64
+	Lon         float64
+	// Population is 0 when unknown.
+	Population int
 }
 
 type Current struct {
@@ -32,157 +36,297 @@ type DailyForecast struct {
 	MaxTempC   float64
 	Condition  string
 	WindMaxKmh float64
+	PrecipMM   float64
+	// Kind is set for date lookups.
+	Kind DataKind
 }
 
+// HourlyForecast is the weather of an hour, Time is in the time zone of the place.
+type HourlyForecast struct {
+	Time         time.Time
+	TempC        float64
+	Condition    string
+	ChanceOfRain int // percent
+	PrecipMM     float64
+	WindKmh      float64
+}
+
+type Alert struct {
+	Event     string
+	Headline  string
+	Severity  string
+	Areas     string
+	Effective time.Time
+	Expires   time.Time
+}
+
+type AirQuality struct {
+	// Category is the US EPA category, from "Good" to "Hazardous".
+	Category string
+	// USAQI is the US air quality index, 0 when the provider only reports the category.
+	USAQI int
+	PM25  float64
+	PM10  float64
+	O3    float64
+	NO2   float64
+}
+
+// DataKind tells how the weather of a day is known.
+type DataKind string
+
+const (
+	KindObserved       DataKind = "observed"
+	KindForecast       DataKind = "forecast"
+	KindClimatological DataKind = "climatological"
+)
+
 type Result struct {
 	Place    Geocode
 	Current  Current
 	Forecast []DailyForecast
+	Hourly   []HourlyForecast
+	Alerts   []Alert
+	// AirQuality is nil unless asked for.
+	AirQuality *AirQuality
+	// Unsupported lists the requested sections the provider has no data for, e.g. "alerts".
+	Unsupported []string
+	// Kind is set for date lookups, whose days are the entries of Forecast. It is the kind of the first
+	// day, see DailyForecast.Kind for the others.
+	Kind DataKind
+	// Note explains how climatological data was computed, e.g. the years averaged.
+	Note string
+	// Provider is the name of the provider that answered.
+	Provider string
+}
+
+// Query is the weather asked to a provider.
+type Query struct {
+	Location string
+	// Place is the location already resolved with a Geocoder, providers then use its coordinates.
+	Place *Geocode
+	// Days of forecast starting today, 0 for the current conditions only.
+	Days int
+	// Hours of hourly forecast starting with the current hour.
+	Hours      int
+	Alerts     bool
+	AirQuality bool
+	// Date asks for the weather of a single day in the past or the future instead, the other
+	// fields but Location are then ignored.
+	Date time.Time
+	// EndDate extends the Date lookup to the days from Date to EndDate.
+	EndDate time.Time
+}
+
+// lastDate is the last day of a date lookup.
+func (q Query) lastDate() time.Time {
+	if q.EndDate.After(q.Date) {
+		return q.EndDate
+	}
+	return q.Date
+}
+
+// Provider is a weather data source.
+type Provider interface {
+	Name() string
+	Fetch(ctx context.Context, q Query) (Result, error)
 }
 
-var HttpClient = &http.Client{Timeout: 8 * time.Second}
-var BaseURL = "https://api.weatherapi.com/v1"
+// forecaster is a provider that knows how many days ahead it forecasts, later dates get long range
+// forecasts or climate data.
+type forecaster interface {
+	forecastDays() int
+}
+
+const (
+	maxForecastDays  = 10
+	maxForecastHours = 48
+	// climateYears is the number of past years averaged for climatological data.
+	climateYears = 10
+)
+
+type options struct {
+	client  *http.Client
+	baseURL string
+}
+
+// Option configures a provider client.
+type Option func(*options)
+
+// WithHTTPClient sends the requests of the provider through client.
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *options) { o.client = client }
+}
+
+// WithBaseURL points the provider to another server, e.g. a test one.
+func WithBaseURL(url string) Option {
+	return func(o *options) { o.baseURL = strings.TrimSuffix(url, "/") }
+}
+
+func newOptions(baseURL string, opts []Option) options {
+	o := options{client: &http.Client{Timeout: 8 * time.Second}, baseURL: baseURL}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// Failover asks the providers in order and returns the first answer.
+type Failover []Provider
+
+func (f Failover) Name() string {
+	names := make([]string, len(f))
+	for i, p := range f {
+		names[i] = p.Name()
+	}
+	return strings.Join(names, ",")
+}
 
-func SetBaseURL(url string) {
-	if url == "" {
-		BaseURL = "https://api.weatherapi.com/v1"
-		return
+// forecastDays is the horizon of the first provider, which answers unless it fails.
+func (f Failover) forecastDays() int {
+	if len(f) == 0 {
+		return 0
+	}
+	if fc, ok := f[0].(forecaster); ok {
+		return fc.forecastDays()
 	}
-	BaseURL = url
+	return 0
 }
 
-func SetHTTPClient(client *http.Client) {
-	if client == nil {
-		HttpClient = &http.Client{Timeout: 8 * time.Second}
-		return
+// Search asks the providers that are Geocoders in order and returns the first answer.
+func (f Failover) Search(ctx context.Context, name string, limit int) ([]Geocode, error) {
+	var errs []error
+	for _, p := range f {
+		g, ok := p.(Geocoder)
+		if !ok {
+			continue
+		}
+		found, err := g.Search(ctx, name, limit)
+		if err == nil {
+			return found, nil
+		}
+		if ctx.Err() != nil {
+			return nil, err
+		}
+
+		slog.WarnContext(ctx, "Geocoder failed", "provider", p.Name(), "error", err)
+		errs = append(errs, fmt.Errorf("%s: %w", p.Name(), err))
+	}
+	if len(errs) == 0 {
+		return nil, ErrNoGeocoder
 	}
-	HttpClient = client
+	return nil, errors.Join(errs...)
 }
 
-func apiKey() (string, error) {
-	k := os.Getenv("WEATHER_API_KEY")
-	if k == "" {
-		return "", errors.New("WEATHER_API_KEY is not set")
+func (f Failover) Fetch(ctx context.Context, q Query) (Result, error) {
+	var errs []error
+	for _, p := range f {
+		res, err := p.Fetch(ctx, q)
+		if err == nil {
+			return res, nil
+		}
+		if ctx.Err() != nil {
+			return Result{}, err
+		}
+
+		slog.WarnContext(ctx, "Weather provider failed", "provider", p.Name(), "error", err)
+		errs = append(errs, fmt.Errorf("%s: %w", p.Name(), err))
+	}
+	if len(errs) == 0 {
+		return Result{}, errors.New("no weather provider configured")
 	}
-	return k, nil
+	return Result{}, errors.Join(errs...)
 }
 
-func Fetch(ctx context.Context, location string, days int) (Result, error) {
-	key, err := apiKey()
-	if err != nil {
-		return Result{}, err
+// FromEnv builds the providers listed in WEATHER_PROVIDERS, in failover order (default
+// "weatherapi,openmeteo"). WeatherAPI is skipped when WEATHER_API_KEY is not set. Answers are cached
+// for WEATHER_CACHE_CURRENT_TTL and WEATHER_CACHE_FORECAST_TTL unless WEATHER_CACHE is "off".
+func FromEnv(opts ...Option) Provider {
+	names := os.Getenv("WEATHER_PROVIDERS")
+	if names == "" {
+		names = "weatherapi,openmeteo"
 	}
 
-	q := url.QueryEscape(location)
-	base := BaseURL
-	var endpoint string
-	if days > 0 {
-		if days > 10 {
-			days = 10
+	var providers Failover
+	for _, name := range strings.Split(names, ",") {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "weatherapi":
+			if key := os.Getenv("WEATHER_API_KEY"); key != "" {
+				providers = append(providers, NewWeatherAPI(key, opts...))
+			} else {
+				slog.Warn("WEATHER_API_KEY is not set, skipping the WeatherAPI provider")
+			}
+		case "openmeteo", "open-meteo":
+			providers = append(providers, NewOpenMeteo(opts...))
+		case "":
+		default:
+			slog.Warn("Unknown weather provider", "provider", name)
 		}
-		endpoint = fmt.Sprintf("%s/forecast.json?key=%s&q=%s&days=%d&aqi=no&alerts=no", base, key, q, days)
-	} else {
-		endpoint = fmt.Sprintf("%s/current.json?key=%s&q=%s&aqi=no", base, key, q)
-	}
-
-	slog.InfoContext(ctx, "Fetching real weather from WeatherAPI...",
-		"location", location,
-		"days", days,
-		"url", endpoint,
-	)
-
-	req, _ := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
-	resp, err := HttpClient.Do(req)
-	if err != nil {
-		slog.ErrorContext(ctx, "WeatherAPI request failed", "error", err)
-		return Result{}, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		slog.WarnContext(ctx, "WeatherAPI non-200 status", "status", resp.Status)
-		return Result{}, fmt.Errorf("weatherapi error: %s", resp.Status)
-	}
-
-	var data struct {
-		Location struct {
-			Name    string  `json:"name"`
-			Country string  `json:"country"`
-			Lat     float64 `json:"lat"`
-			Lon     float64 `json:"lon"`
-		} `json:"location"`
-		Current struct {
-			TempC      float64 `json:"temp_c"`
-			WindKph    float64 `json:"wind_kph"`
-			WindDegree float64 `json:"wind_degree"`
-			Condition  struct {
-				Text string `json:"text"`
-			} `json:"condition"`
-		} `json:"current"`
-		Forecast struct {
-			Forecastday []struct {
-				Date string `json:"date"`
-				Day  struct {
-					MaxtempC   float64 `json:"maxtemp_c"`
-					MintempC   float64 `json:"mintemp_c"`
-					MaxwindKph float64 `json:"maxwind_kph"`
-					Condition  struct {
-						Text string `json:"text"`
-					} `json:"condition"`
-				} `json:"day"`
-			} `json:"forecastday"`
-		} `json:"forecast"`
-		Error *struct {
-			Code int    `json:"code"`
-			Msg  string `json:"message"`
-		} `json:"error"`
-	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
-		slog.ErrorContext(ctx, "Failed to decode WeatherAPI response", "error", err)
-		return Result{}, err
-	}
-	if data.Error != nil {
-		slog.ErrorContext(ctx, "WeatherAPI returned an error", "code", data.Error.Code, "msg", data.Error.Msg)
-		return Result{}, fmt.Errorf("weatherapi: %s (code %d)", data.Error.Msg, data.Error.Code)
-	}
-
-	slog.InfoContext(ctx, "WeatherAPI data parsed successfully",
-		"city", data.Location.Name,
-		"country", data.Location.Country,
-		"temp_c", data.Current.TempC,
-		"condition", data.Current.Condition.Text,
-	)
-
-	res := Result{
-		Place: Geocode{
-			Name:    data.Location.Name,
-			Country: data.Location.Country,
-			Lat:     data.Location.Lat,
-			Lon:     data.Location.Lon,
-		},
-		Current: Current{
-			TemperatureC: data.Current.TempC,
-			WindSpeedKmh: data.Current.WindKph,
-			WindDirDeg:   data.Current.WindDegree,
-			Condition:    data.Current.Condition.Text,
-		},
-	}
-
-	if days > 0 && len(data.Forecast.Forecastday) > 0 {
-		for _, d := range data.Forecast.Forecastday {
-			dt, _ := time.Parse("2006-01-02", d.Date)
-			res.Forecast = append(res.Forecast, DailyForecast{
-				Date:       dt,
-				MinTempC:   d.Day.MintempC,
-				MaxTempC:   d.Day.MaxtempC,
-				WindMaxKmh: d.Day.MaxwindKph,
-				Condition:  d.Day.Condition.Text,
-			})
+	}
+
+	if len(providers) == 0 {
+		providers = Failover{NewOpenMeteo(opts...)}
+	}
+
+	if strings.EqualFold(os.Getenv("WEATHER_CACHE"), "off") {
+		return providers
+	}
+	currentTTL, _ := time.ParseDuration(os.Getenv("WEATHER_CACHE_CURRENT_TTL"))
+	forecastTTL, _ := time.ParseDuration(os.Getenv("WEATHER_CACHE_FORECAST_TTL"))
+	return NewCache(providers, currentTTL, forecastTTL)
+}
+
+func clampDays(days int) int {
+	return max(0, min(days, maxForecastDays))
+}
+
+func clampHours(hours int) int {
+	return max(0, min(hours, maxForecastHours))
+}
+
+// placeZone is the time zone a provider names for a place, a fixed offset in seconds east of UTC when the
+// name is unknown.
+func placeZone(name string, offset int) *time.Location {
+	if name != "" {
+		if loc, err := time.LoadLocation(name); err == nil {
+			return loc
 		}
+	}
+	return time.FixedZone(name, offset)
+}
+
+// daysFromToday is the number of days from today (UTC) to the day of date, negative in the past.
+func daysFromToday(date time.Time) int {
+	y, m, d := time.Now().UTC().Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	y, m, d = date.Date()
+	return int(time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Sub(today).Hours() / 24)
+}
+
+var epaCategories = []string{"Good", "Moderate", "Unhealthy for sensitive groups", "Unhealthy", "Very unhealthy", "Hazardous"}
+
+// epaCategory names a US EPA index from 1 (good) to 6 (hazardous).
+func epaCategory(index int) string {
+	if index < 1 || index > len(epaCategories) {
+		return "Unknown"
+	}
+	return epaCategories[index-1]
+}
 
-		slog.InfoContext(ctx, "Forecast parsed", "days", len(res.Forecast))
+// usAQICategory names the category of a US AQI value.
+func usAQICategory(aqi int) string {
+	for i, limit := range []int{50, 100, 150, 200, 300} {
+		if aqi <= limit {
+			return epaCategories[i]
+		}
 	}
+	return epaCategories[5]
+}
 
-	return res, nil
+// requestError drops the URL from transport errors, it may carry an API key.
+func requestError(provider string, err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+	return fmt.Errorf("%s request failed: %w", provider, err)
 }
@@ -1,12 +1,16 @@
 package weather_test
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
-	"os"
 	"testing"
+	"time"
 
 	"github.com/matteo-nyapa/tech-challenge-acai/internal/weather"
 	"github.com/stretchr/testify/require"
@@ -33,19 +37,9 @@ func TestFetch_CurrentWeather(t *testing.T) {
 	}))
 	defer ts.Close()
 
-	os.Setenv("WEATHER_API_KEY", "fake-key")
+	wa := weather.NewWeatherAPI("fake-key", weather.WithBaseURL(ts.URL), weather.WithHTTPClient(ts.Client()))
 
-	oldClient := weather.HttpClient
-	weather.HttpClient = ts.Client()
-	weather.BaseURL = ts.URL
-	defer func() { weather.HttpClient = oldClient }()
-
-	oldBase := "https://api.weatherapi.com/v1"
-	defer func() {
-		_ = oldBase
-	}()
-
-	res, err := weather.Fetch(context.Background(), "Barcelona", 0)
+	res, err := wa.Fetch(context.Background(), weather.Query{Location: "Barcelona"})
 	require.NoError(t, err)
 	require.Equal(t, "Barcelona", res.Place.Name)
 	require.Equal(t, "Spain", res.Place.Country)
@@ -97,18 +91,9 @@ func TestFetch_ForecastWeather(t *testing.T) {
 	}))
 	defer ts.Close()
 
-	os.Setenv("WEATHER_API_KEY", "fake-key")
+	wa := weather.NewWeatherAPI("fake-key", weather.WithBaseURL(ts.URL), weather.WithHTTPClient(ts.Client()))
 
-	oldClient := weather.HttpClient
-	oldBase := weather.BaseURL
-	weather.HttpClient = ts.Client()
-	weather.BaseURL = ts.URL
-	defer func() {
-		weather.HttpClient = oldClient
-		weather.BaseURL = oldBase
-	}()
-
-	res, err := weather.Fetch(context.Background(), "Madrid", 3)
+	res, err := wa.Fetch(context.Background(), weather.Query{Location: "Madrid", Days: 3})
 	require.NoError(t, err)
 	require.Equal(t, "Madrid", res.Place.Name)
 	require.Equal(t, "Spain", res.Place.Country)
@@ -118,6 +103,40 @@ func TestFetch_ForecastWeather(t *testing.T) {
 	require.Equal(t, "Cloudy", res.Forecast[1].Condition)
 }
 
+func TestFetch_HourlyAcrossMidnight(t *testing.T) {
+	hours := func(date string, from, to int) []any {
+		var hs []any
+		for h := from; h <= to; h++ {
+			hs = append(hs, map[string]any{"time": fmt.Sprintf("%s %02d:00", date, h), "temp_c": float64(h)})
+		}
+		return hs
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/forecast.json", r.URL.Path)
+		require.Equal(t, "2", r.URL.Query().Get("days"))
+		require.Equal(t, "no", r.URL.Query().Get("alerts"))
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"location": map[string]any{"name": "Madrid", "localtime": "2025-10-28 22:15"},
+			"forecast": map[string]any{"forecastday": []any{
+				map[string]any{"date": "2025-10-28", "hour": hours("2025-10-28", 0, 23)},
+				map[string]any{"date": "2025-10-29", "hour": hours("2025-10-29", 0, 23)},
+			}},
+		})
+	}))
+	defer ts.Close()
+
+	wa := weather.NewWeatherAPI("fake-key", weather.WithBaseURL(ts.URL), weather.WithHTTPClient(ts.Client()))
+
+	res, err := wa.Fetch(context.Background(), weather.Query{Location: "Madrid", Hours: 4})
+	require.NoError(t, err)
+	require.Empty(t, res.Forecast)
+	require.Len(t, res.Hourly, 4)
+	require.Equal(t, "2025-10-28 22:00", res.Hourly[0].Time.Format("2006-01-02 15:04"))
+	require.Equal(t, "2025-10-29 01:00", res.Hourly[3].Time.Format("2006-01-02 15:04"))
+	require.Nil(t, res.AirQuality)
+	require.Nil(t, res.Alerts)
+}
+
 func TestFetch_ErrorResponse(t *testing.T) {
 	mockResp := map[string]any{
 		"error": map[string]any{
@@ -132,14 +151,157 @@ func TestFetch_ErrorResponse(t *testing.T) {
 	}))
 	defer ts.Close()
 
-	os.Setenv("WEATHER_API_KEY", "fake-key")
-
-	oldClient := weather.HttpClient
-	weather.HttpClient = ts.Client()
-	weather.BaseURL = ts.URL
-	defer func() { weather.HttpClient = oldClient }()
+	wa := weather.NewWeatherAPI("fake-key", weather.WithBaseURL(ts.URL), weather.WithHTTPClient(ts.Client()))
 
-	_, err := weather.Fetch(context.Background(), "UnknownCity", 0)
+	_, err := wa.Fetch(context.Background(), weather.Query{Location: "UnknownCity"})
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "No matching location")
 }
+
+type stubProvider struct {
+	name  string
+	err   error
+	calls int
+}
+
+func (s *stubProvider) Name() string { return s.name }
+
+func (s *stubProvider) Fetch(ctx context.Context, q weather.Query) (weather.Result, error) {
+	s.calls++
+	if s.err != nil {
+		return weather.Result{}, s.err
+	}
+	return weather.Result{Place: weather.Geocode{Name: q.Location}, Provider: s.name}, nil
+}
+
+func TestFailover(t *testing.T) {
+	down := &stubProvider{name: "down", err: errors.New("503 Service Unavailable")}
+	up := &stubProvider{name: "up"}
+	unused := &stubProvider{name: "unused"}
+
+	res, err := weather.Failover{down, up, unused}.Fetch(context.Background(), weather.Query{Location: "Rome"})
+	require.NoError(t, err)
+	require.Equal(t, "up", res.Provider)
+	require.Equal(t, 1, down.calls)
+	require.Zero(t, unused.calls)
+
+	_, err = weather.Failover{down, &stubProvider{name: "also-down", err: errors.New("timeout")}}.
+		Fetch(context.Background(), weather.Query{Location: "Rome"})
+	require.ErrorContains(t, err, "down: 503 Service Unavailable")
+	require.ErrorContains(t, err, "also-down: timeout")
+}
+
+type stubGeocoder struct {
+	stubProvider
+	found []weather.Geocode
+}
+
+func (s *stubGeocoder) Search(ctx context.Context, name string, limit int) ([]weather.Geocode, error) {
+	s.calls++
+	return s.found, s.err
+}
+
+func TestFailover_SearchFallsBack(t *testing.T) {
+	down := &stubGeocoder{stubProvider: stubProvider{name: "down", err: errors.New("503 Service Unavailable")}}
+	up := &stubGeocoder{stubProvider: stubProvider{name: "up"}, found: []weather.Geocode{{Name: "Rome"}}}
+	unused := &stubGeocoder{stubProvider: stubProvider{name: "unused"}}
+
+	found, err := weather.Failover{down, &stubProvider{name: "fetch-only"}, up, unused}.Search(context.Background(), "Rome", 5)
+	require.NoError(t, err)
+	require.Equal(t, []weather.Geocode{{Name: "Rome"}}, found)
+	require.Equal(t, 1, down.calls)
+	require.Zero(t, unused.calls)
+
+	_, err = weather.Failover{down, &stubGeocoder{stubProvider: stubProvider{name: "also-down", err: errors.New("timeout")}}}.
+		Search(context.Background(), "Rome", 5)
+	require.ErrorContains(t, err, "down: 503 Service Unavailable")
+	require.ErrorContains(t, err, "also-down: timeout")
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("WEATHER_API_KEY", "")
+	t.Setenv("WEATHER_PROVIDERS", "")
+	require.Equal(t, "openmeteo", weather.FromEnv().Name())
+
+	t.Setenv("WEATHER_API_KEY", "fake-key")
+	require.Equal(t, "weatherapi,openmeteo", weather.FromEnv().Name())
+
+	t.Setenv("WEATHER_PROVIDERS", "openmeteo, weatherapi")
+	require.Equal(t, "openmeteo,weatherapi", weather.FromEnv().Name())
+}
+
+func TestWeatherAPI_NeverLogsTheKey(t *testing.T) {
+	var logs bytes.Buffer
+	old := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+	defer slog.SetDefault(old)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "super-secret", r.URL.Query().Get("key"))
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	wa := weather.NewWeatherAPI("super-secret", weather.WithBaseURL(ts.URL), weather.WithHTTPClient(ts.Client()))
+
+	_, err := wa.Fetch(context.Background(), weather.Query{Location: "Paris", Days: 2})
+	require.Error(t, err)
+	require.NotContains(t, err.Error(), "super-secret")
+
+	// transport errors carry the request URL
+	ts.Close()
+	_, err = wa.Fetch(context.Background(), weather.Query{Location: "Paris"})
+	require.Error(t, err)
+	require.NotContains(t, err.Error(), "super-secret")
+
+	require.Contains(t, logs.String(), "q=Paris")
+	require.NotContains(t, logs.String(), "super-secret")
+}
+
+func TestWeatherAPI_Date(t *testing.T) {
+	var got *http.Request
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"location": map[string]any{"name": "Rome", "country": "Italy"},
+			"forecast": map[string]any{"forecastday": []any{
+				map[string]any{"date": time.Now().UTC().Format(time.DateOnly)},
+				map[string]any{"date": r.URL.Query().Get("dt"), "day": map[string]any{
+					"maxtemp_c": 19.0, "mintemp_c": 9.0, "totalprecip_mm": 4.2, "condition": map[string]any{"text": "Patchy rain"},
+				}},
+			}},
+		})
+	}))
+	defer ts.Close()
+	wa := weather.NewWeatherAPI("fake-key", weather.WithBaseURL(ts.URL), weather.WithHTTPClient(ts.Client()))
+	today := time.Now().UTC()
+
+	tests := []struct {
+		name string
+		date time.Time
+		path string
+		days string
+		kind weather.DataKind
+	}{
+		{"last tuesday", today.AddDate(0, 0, -5), "/history.json", "", weather.KindObserved},
+		{"in two days", today.AddDate(0, 0, 2), "/forecast.json", "3", weather.KindForecast},
+		{"next month", today.AddDate(0, 1, 0), "/future.json", "", weather.KindForecast},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := wa.Fetch(context.Background(), weather.Query{Location: "Rome", Date: tt.date, Days: 7, Hours: 5})
+			require.NoError(t, err)
+			require.Equal(t, tt.path, got.URL.Path)
+			require.Equal(t, tt.date.Format(time.DateOnly), got.URL.Query().Get("dt"))
+			require.Equal(t, tt.days, got.URL.Query().Get("days"))
+			require.Equal(t, tt.kind, res.Kind)
+			require.Len(t, res.Forecast, 1)
+			require.Equal(t, "Patchy rain", res.Forecast[0].Condition)
+			require.InDelta(t, 4.2, res.Forecast[0].PrecipMM, 0.001)
+			require.Empty(t, res.Hourly)
+		})
+	}
+
+	got = nil
+	_, err := wa.Fetch(context.Background(), weather.Query{Location: "Rome", Date: today.AddDate(2, 0, 0)})
+	require.ErrorContains(t, err, "300 days")
+	require.Nil(t, got)
+}
@@ -0,0 +1,356 @@
+package weather
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// WeatherAPI is the client of https://www.weatherapi.com.
+type WeatherAPI struct {
+	key     string
+	baseURL string
+	client  *http.Client
+}
+
+func NewWeatherAPI(key string, opts ...Option) *WeatherAPI {
+	o := newOptions("https://api.weatherapi.com/v1", opts)
+	return &WeatherAPI{key: key, baseURL: o.baseURL, client: o.client}
+}
+
+func (w *WeatherAPI) Name() string { return "weatherapi" }
+
+func (w *WeatherAPI) forecastDays() int { return weatherAPIForecastDays }
+
+func (w *WeatherAPI) Fetch(ctx context.Context, q Query) (Result, error) {
+	if !q.Date.IsZero() {
+		return w.fetchDays(ctx, q)
+	}
+
+	days := clampDays(q.Days)
+	hours := clampHours(q.Hours)
+
+	// hourly data and alerts come with the forecast, the next hours may span until the day after tomorrow
+	fetchDays := days
+	if hours > 0 {
+		fetchDays = max(fetchDays, (hours+23)/24+1)
+	}
+	if q.Alerts {
+		fetchDays = max(fetchDays, 1)
+	}
+
+	params := url.Values{"q": {location(q)}, "aqi": {yesNo(q.AirQuality)}}
+	path := "/current.json"
+	if fetchDays > 0 {
+		path = "/forecast.json"
+		params.Set("days", strconv.Itoa(fetchDays))
+		params.Set("alerts", yesNo(q.Alerts))
+	}
+
+	data, err := w.get(ctx, path, params, "days", days)
+	if err != nil {
+		return Result{}, err
+	}
+
+	slog.InfoContext(ctx, "WeatherAPI data parsed successfully",
+		"city", data.Location.Name,
+		"country", data.Location.Country,
+		"temp_c", data.Current.TempC,
+		"condition", data.Current.Condition.Text,
+	)
+
+	res := Result{
+		Place: data.place(q),
+		Current: Current{
+			TemperatureC: data.Current.TempC,
+			WindSpeedKmh: data.Current.WindKph,
+			WindDirDeg:   data.Current.WindDegree,
+			Condition:    data.Current.Condition.Text,
+		},
+		Provider: w.Name(),
+	}
+
+	for i, d := range data.Forecast.Forecastday {
+		if i >= days {
+			break
+		}
+		res.Forecast = append(res.Forecast, d.daily())
+	}
+	if days > 0 {
+		slog.InfoContext(ctx, "Forecast parsed", "days", len(res.Forecast))
+	}
+
+	if hours > 0 {
+		zone := placeZone(data.Location.TzID, 0)
+		now, _ := time.ParseInLocation(localTimeLayout, data.Location.Localtime, zone)
+		now = now.Truncate(time.Hour)
+		for _, d := range data.Forecast.Forecastday {
+			for _, h := range d.Hour {
+				t, err := time.ParseInLocation(localTimeLayout, h.Time, zone)
+				if err != nil || t.Before(now) || len(res.Hourly) >= hours {
+					continue
+				}
+				res.Hourly = append(res.Hourly, HourlyForecast{
+					Time:         t,
+					TempC:        h.TempC,
+					Condition:    h.Condition.Text,
+					ChanceOfRain: h.ChanceOfRain,
+					PrecipMM:     h.PrecipMM,
+					WindKmh:      h.WindKph,
+				})
+			}
+		}
+	}
+
+	if q.Alerts {
+		res.Alerts = []Alert{}
+		for _, a := range data.Alerts.Alert {
+			effective, _ := time.Parse(time.RFC3339, a.Effective)
+			expires, _ := time.Parse(time.RFC3339, a.Expires)
+			res.Alerts = append(res.Alerts, Alert{
+				Event:     a.Event,
+				Headline:  a.Headline,
+				Severity:  a.Severity,
+				Areas:     a.Areas,
+				Effective: effective,
+				Expires:   expires,
+			})
+		}
+	}
+
+	if aq := data.Current.AirQuality; q.AirQuality && aq != nil {
+		res.AirQuality = &AirQuality{
+			Category: epaCategory(aq.EPAIndex),
+			PM25:     aq.PM25,
+			PM10:     aq.PM10,
+			O3:       aq.O3,
+			NO2:      aq.NO2,
+		}
+	}
+
+	return res, nil
+}
+
+// WeatherAPI serves forecasts up to 14 days ahead and long range ones from 14 to 300 days ahead.
+const (
+	weatherAPIForecastDays = 14
+	weatherAPIFutureDays   = 300
+)
+
+// fetchDays looks the days of a date lookup up one by one, but for the days within the forecast that come
+// with a single request.
+func (w *WeatherAPI) fetchDays(ctx context.Context, q Query) (Result, error) {
+	end := q.lastDate()
+	var res Result
+	for date := q.Date; !date.After(end); {
+		last := date
+		if delta := daysFromToday(date); delta >= 0 && delta < weatherAPIForecastDays {
+			for next := last.AddDate(0, 0, 1); !next.After(end) && daysFromToday(next) < weatherAPIForecastDays; next = next.AddDate(0, 0, 1) {
+				last = next
+			}
+		}
+
+		span, err := w.fetchSpan(ctx, q, date, last)
+		if err != nil {
+			return Result{}, err
+		}
+		if res.Forecast == nil {
+			res = span
+		} else {
+			res.Forecast = append(res.Forecast, span.Forecast...)
+		}
+		date = last.AddDate(0, 0, 1)
+	}
+	return res, nil
+}
+
+// fetchSpan routes the days from date to last to the history, forecast or future endpoint, only the
+// forecast one serves several days.
+func (w *WeatherAPI) fetchSpan(ctx context.Context, q Query, date, last time.Time) (Result, error) {
+	params := url.Values{"q": {location(q)}, "dt": {date.Format(time.DateOnly)}}
+	kind := KindForecast
+	var path string
+	switch delta := daysFromToday(date); {
+	case delta < 0:
+		path, kind = "/history.json", KindObserved
+	case delta < weatherAPIForecastDays:
+		path = "/forecast.json"
+		params.Set("days", strconv.Itoa(daysFromToday(last)+1))
+		if last.After(date) {
+			// dt restricts the forecast to a single day
+			params.Del("dt")
+		}
+	case delta <= weatherAPIFutureDays:
+		path = "/future.json"
+	default:
+		return Result{}, fmt.Errorf("weatherapi: no forecast more than %d days ahead", weatherAPIFutureDays)
+	}
+
+	data, err := w.get(ctx, path, params, "date", date.Format(time.DateOnly))
+	if err != nil {
+		return Result{}, err
+	}
+
+	res := Result{Place: data.place(q), Kind: kind, Provider: w.Name()}
+	for _, d := range data.Forecast.Forecastday {
+		if d.Date >= date.Format(time.DateOnly) && d.Date <= last.Format(time.DateOnly) {
+			f := d.daily()
+			f.Kind = kind
+			res.Forecast = append(res.Forecast, f)
+		}
+	}
+	if len(res.Forecast) == 0 {
+		return Result{}, fmt.Errorf("weatherapi: no data for %s", date.Format(time.DateOnly))
+	}
+	return res, nil
+}
+
+type weatherAPICondition struct {
+	Text string `json:"text"`
+}
+
+type weatherAPIResponse struct {
+	Location struct {
+		Name      string  `json:"name"`
+		Region    string  `json:"region"`
+		Country   string  `json:"country"`
+		Lat       float64 `json:"lat"`
+		Lon       float64 `json:"lon"`
+		TzID      string  `json:"tz_id"`
+		Localtime string  `json:"localtime"`
+	} `json:"location"`
+	Current struct {
+		TempC      float64             `json:"temp_c"`
+		WindKph    float64             `json:"wind_kph"`
+		WindDegree float64             `json:"wind_degree"`
+		Condition  weatherAPICondition `json:"condition"`
+		AirQuality *struct {
+			PM25     float64 `json:"pm2_5"`
+			PM10     float64 `json:"pm10"`
+			O3       float64 `json:"o3"`
+			NO2      float64 `json:"no2"`
+			EPAIndex int     `json:"us-epa-index"`
+		} `json:"air_quality"`
+	} `json:"current"`
+	Forecast struct {
+		Forecastday []weatherAPIDay `json:"forecastday"`
+	} `json:"forecast"`
+	Alerts struct {
+		Alert []struct {
+			Headline  string `json:"headline"`
+			Severity  string `json:"severity"`
+			Event     string `json:"event"`
+			Areas     string `json:"areas"`
+			Effective string `json:"effective"`
+			Expires   string `json:"expires"`
+		} `json:"alert"`
+	} `json:"alerts"`
+	Error *struct {
+		Code int    `json:"code"`
+		Msg  string `json:"message"`
+	} `json:"error"`
+}
+
+// place is the resolved place of the query if any, WeatherAPI names the nearest town to coordinates.
+func (r weatherAPIResponse) place(q Query) Geocode {
+	if q.Place != nil {
+		return *q.Place
+	}
+	return Geocode{
+		Name:    r.Location.Name,
+		Region:  r.Location.Region,
+		Country: r.Location.Country,
+		Lat:     r.Location.Lat,
+		Lon:     r.Location.Lon,
+	}
+}
+
+// location is the q parameter of a query, the coordinates of resolved places.
+func location(q Query) string {
+	if q.Place != nil {
+		return strconv.FormatFloat(q.Place.Lat, 'f', 4, 64) + "," + strconv.FormatFloat(q.Place.Lon, 'f', 4, 64)
+	}
+	return q.Location
+}
+
+type weatherAPIDay struct {
+	Date string `json:"date"`
+	Day  struct {
+		MaxtempC      float64             `json:"maxtemp_c"`
+		MintempC      float64             `json:"mintemp_c"`
+		MaxwindKph    float64             `json:"maxwind_kph"`
+		TotalprecipMM float64             `json:"totalprecip_mm"`
+		Condition     weatherAPICondition `json:"condition"`
+	} `json:"day"`
+	Hour []struct {
+		Time         string              `json:"time"`
+		TempC        float64             `json:"temp_c"`
+		Condition    weatherAPICondition `json:"condition"`
+		ChanceOfRain int                 `json:"chance_of_rain"`
+		PrecipMM     float64             `json:"precip_mm"`
+		WindKph      float64             `json:"wind_kph"`
+	} `json:"hour"`
+}
+
+func (d weatherAPIDay) daily() DailyForecast {
+	dt, _ := time.Parse(time.DateOnly, d.Date)
+	return DailyForecast{
+		Date:       dt,
+		MinTempC:   d.Day.MintempC,
+		MaxTempC:   d.Day.MaxtempC,
+		WindMaxKmh: d.Day.MaxwindKph,
+		PrecipMM:   d.Day.TotalprecipMM,
+		Condition:  d.Day.Condition.Text,
+	}
+}
+
+// get calls an endpoint, logArgs are logged along with the location.
+func (w *WeatherAPI) get(ctx context.Context, path string, params url.Values, logArgs ...any) (weatherAPIResponse, error) {
+	// the key is added last so the logged query never contains it
+	attrs := append([]any{"location", params.Get("q")}, logArgs...)
+	slog.InfoContext(ctx, "Fetching real weather from WeatherAPI...", append(attrs, "endpoint", path+"?"+params.Encode())...)
+	params.Set("key", w.key)
+
+	var data weatherAPIResponse
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, w.baseURL+path+"?"+params.Encode(), nil)
+	if err != nil {
+		return data, requestError("weatherapi", err)
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		err = requestError("weatherapi", err)
+		slog.ErrorContext(ctx, "WeatherAPI request failed", "error", err)
+		return data, err
+	}
+	defer resp.Body.Close()
+
+	// errors come with a JSON body too, e.g. 400 for unknown locations
+	decodeErr := json.NewDecoder(resp.Body).Decode(&data)
+	if data.Error != nil {
+		slog.ErrorContext(ctx, "WeatherAPI returned an error", "code", data.Error.Code, "msg", data.Error.Msg)
+		return data, fmt.Errorf("weatherapi: %s (code %d)", data.Error.Msg, data.Error.Code)
+	}
+	if resp.StatusCode != http.StatusOK {
+		slog.WarnContext(ctx, "WeatherAPI non-200 status", "status", resp.Status)
+		return data, fmt.Errorf("weatherapi error: %s", resp.Status)
+	}
+	if decodeErr != nil {
+		slog.ErrorContext(ctx, "Failed to decode WeatherAPI response", "error", decodeErr)
+		return data, decodeErr
+	}
+	return data, nil
+}
+
+const localTimeLayout = "2006-01-02 15:04"
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}